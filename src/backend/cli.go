@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	appConfig "main/internal/config"
+	"main/internal/logging"
+	"main/internal/service"
+	"text/tabwriter"
+)
+
+// runCommand runs a local command instead of starting the Lambda handler
+func runCommand(args []string, out io.Writer) error {
+	switch args[0] {
+	case "preview-email":
+		return previewEmail(args[1:], out)
+	case "run-job":
+		return runJob(args[1:], out)
+	case "config":
+		return configCommand(args[1:], out)
+	default:
+		return fmt.Errorf("unknown command %q, available commands: config, preview-email, run-job", args[0])
+	}
+}
+
+// runJob runs a background job once against the configured AWS resources, or lists the jobs:
+//
+//	bootstrap run-job [--key=value...] [NAME]
+func runJob(args []string, out io.Writer) error {
+	ctx := context.Background()
+	configFlags, args := appConfig.SplitFlags(args)
+	awsCfg, appCfg, err := loadConfig(ctx, configFlags)
+	if err != nil {
+		return err
+	}
+
+	tracer := setupTelemetry(ctx, appCfg)
+	defer tracer.Shutdown(ctx)
+	_, jobs := setup(awsCfg, appCfg)
+	if len(args) == 0 {
+		for _, name := range jobs.Names() {
+			job := jobs.Config(name)
+			fmt.Fprintf(out, "%-16s enabled=%t timeout=%s batch_size=%d\n", name, job.Enabled, job.Timeout, job.BatchSize)
+		}
+		return nil
+	}
+	return jobs.Run(ctx, args[0])
+}
+
+// configCommand prints the effective configuration with secrets redacted and the source of
+// each setting, then reports any invalid or missing setting:
+//
+//	bootstrap config print [--config=FILE] [--key=value...]
+func configCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: config print [--config=FILE] [--key=value...]")
+	}
+
+	appCfg, err := appConfig.Load(args[1:])
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, setting := range appCfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s=%s\n", setting.Source, setting.Key, setting.Value)
+	}
+	w.Flush()
+	return err
+}
+
+// previewEmail renders a notification email template with sample data:
+//
+//	bootstrap preview-email [-dir DIR] [-format text|html] like|contact
+func previewEmail(args []string, out io.Writer) error {
+	appCfg, err := appConfig.Parse(nil)
+	if err != nil {
+		return err
+	}
+	logging.SetLevel(logging.ParseLevel(appCfg.LogLevel, appCfg.Environment))
+
+	flags := flag.NewFlagSet("preview-email", flag.ContinueOnError)
+	dir := flags.String("dir", appCfg.EmailTemplateDir, "directory with template overrides")
+	format := flags.String("format", "text", "body to print: text or html")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	notificationType := flags.Arg(0)
+	if notificationType == "" {
+		notificationType = "contact"
+	}
+
+	templates, err := service.LoadEmailTemplates(*dir)
+	if err != nil {
+		return err
+	}
+	email, err := templates.Render(notificationType, service.SampleEmailData(appCfg.SiteURL))
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		fmt.Fprintf(out, "Subject: %s\n\n%s", email.Subject, email.Text)
+	case "html":
+		if email.HTML == "" {
+			return fmt.Errorf("no HTML template for %s", notificationType)
+		}
+		fmt.Fprint(out, email.HTML)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return nil
+}
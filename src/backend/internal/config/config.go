@@ -1,37 +1,349 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DynamoDBTable           string
-	SessionTable            string
-	RecaptchaSecretKey      string
-	SESRegion               string
-	NotificationDstEmail    string
-	NotificationSrcEmail    string
-	NotificationPhoneNumber string
-	Environment             string
+	DynamoDBTable            string
+	SessionTable             string
+	InboxTable               string
+	OutboxTable              string
+	OutboxMaxAttempts        int
+	OutboxBaseDelay          time.Duration
+	OutboxMaxDelay           time.Duration
+	BlocklistTable           string
+	BlocklistCacheTTL        time.Duration
+	CaptchaProvider          string
+	CaptchaSecretKey         string
+	CaptchaMinScore          float64
+	CaptchaExpectedAction    string
+	CaptchaExpectedHostname  string
+	CaptchaMaxAge            time.Duration
+	CaptchaVerifyURL         string
+	CaptchaTimeout           time.Duration
+	SESRegion                string
+	NotificationDstEmail     string
+	NotificationSrcEmail     string
+	NotificationPhoneNumber  string
+	NotificationTimeout      time.Duration
+	NotificationTimezone     string
+	QuietHours               QuietHours
+	LikeNotificationMode     string // "immediate" or "digest"
+	DigestSkipEmpty          bool
+	EmailNotificationTypes   []string // notification types sent to each channel
+	SMSNotificationTypes     []string
+	SlackWebhookURL          string
+	SlackNotificationTypes   []string
+	DiscordWebhookURL        string
+	DiscordNotificationTypes []string
+	WebhookURL               string
+	WebhookSecret            string
+	WebhookNotificationTypes []string
+	SMSDefaultCountryCode    string
+	SMSSenderID              string
+	AutoReplyEnabled         bool
+	AutoReplySubject         string
+	EmailTemplateDir         string
+	SiteURL                  string
+	Environment              string
+	LogLevel                 string
+	TracingExporter          string
+	TracingEndpoint          string
+	CSRFSecret               string
+	AdminAPIToken            string
+	SecretsFile              string        // local stand-in for SSM and Secrets Manager
+	SecretsRefreshInterval   time.Duration // how long resolved secrets are cached
+	RateLimitTable           string
+	RateLimits               map[string]RateLimitRule
+	FormTokenSecret          string
+	SpamThreshold            float64
+	SpamMinFillTime          time.Duration
+	SpamMaxFormAge           time.Duration // how long a form token stays valid
+	SpamMaxLinks             int
+	SpamBlockedKeywords      []string
+	SpamBlockedPatterns      []string
+	BotIPRangesFile          string
+	BotMinSessionAge         time.Duration
+	Jobs                     map[string]JobConfig
+	FeatureFlags             map[string]FeatureFlag
+	FeatureFlagsDynamoDB     bool // read overrides from the feature_flags item of the counters table
+	FeatureFlagsCacheTTL     time.Duration
+
+	settings       []Setting            // as resolved by Load, for config print
+	secretRefs     map[string]SecretRef // secret settings that reference SSM or Secrets Manager
+	secretResolver *SecretResolver      // resolves secretRefs for Secret
+}
+
+// JobConfig configures a scheduled background job
+type JobConfig struct {
+	Enabled   bool
+	Timeout   time.Duration
+	BatchSize int // upper bound on the items one run processes
+}
+
+// RateLimitRule allows Limit requests per Window, counted separately per source IP and per session
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Rate limits are keyed by API route, or by limiter name for limits that aren't per route.
+// Admin routes are limited before the token is checked, which also slows down token guessing.
+const defaultRateLimits = "/api/session=30/1m,/api/ready=12/1m,/api/contact=5/1h," +
+	"/api/admin/messages=30/1m,/api/admin/messages/{id}=60/1m,/api/admin/blocklist=30/1m," +
+	"auto_reply=1/24h,duplicate_message=1/24h,notification_dedup=1/1h"
+
+// FeatureFlag turns a feature on for Rollout percent of sessions: 100 is on, 0 is off
+type FeatureFlag struct {
+	Rollout int
+}
+
+// Every feature is on unless FEATURE_FLAGS or the feature_flags item turns it off
+const defaultFeatureFlags = "likes=on,contact=on,sms=on,digest=on,bot_visits=on"
+
+// ParseFeatureFlag parses "on", "off" or a rollout percentage such as "25%"
+func ParseFeatureFlag(value string) (FeatureFlag, error) {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "on", "true":
+		return FeatureFlag{Rollout: 100}, nil
+	case "off", "false":
+		return FeatureFlag{Rollout: 0}, nil
+	}
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		rollout, err := strconv.Atoi(strings.TrimSpace(percent))
+		if err == nil && rollout >= 0 && rollout <= 100 {
+			return FeatureFlag{Rollout: rollout}, nil
+		}
+	}
+	return FeatureFlag{}, fmt.Errorf("invalid feature flag %q, expected on, off or a percentage", value)
+}
+
+// parseFeatureFlags parses flags in the form "name=state,..." e.g. "likes=off,bot_visits=25%"
+func parseFeatureFlags(value string) (map[string]FeatureFlag, error) {
+	flags := make(map[string]FeatureFlag)
+	var errs []error
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, state, ok := strings.Cut(entry, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("malformed feature flag %q", entry))
+			continue
+		}
+		flag, err := ParseFeatureFlag(state)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		flags[strings.TrimSpace(name)] = flag
+	}
+	return flags, errors.Join(errs...)
+}
+
+// QuietHours is a daily period, as offsets from midnight, during which notifications are held back.
+// End may be before Start for periods spanning midnight. It is disabled when Start equals End.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// parseQuietHours parses a range like "22:00-07:00"
+func parseQuietHours(value string) (QuietHours, error) {
+	if value == "" {
+		return QuietHours{}, nil
+	}
+	startStr, endStr, ok := strings.Cut(value, "-")
+	if ok {
+		start, err1 := time.Parse("15:04", strings.TrimSpace(startStr))
+		end, err2 := time.Parse("15:04", strings.TrimSpace(endStr))
+		if err1 == nil && err2 == nil {
+			midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+			return QuietHours{Start: start.Sub(midnight), End: end.Sub(midnight)}, nil
+		}
+	}
+	return QuietHours{}, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", value)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// parseRateLimits parses rules in the form "name=limit/window,..." e.g. "/api/contact=5/1h"
+func parseRateLimits(value string) (map[string]RateLimitRule, error) {
+	rules := make(map[string]RateLimitRule)
+	var errs []error
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, rule, ok := strings.Cut(entry, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("malformed rate limit %q", entry))
+			continue
+		}
+		limitStr, windowStr, ok := strings.Cut(rule, "/")
+		if !ok {
+			errs = append(errs, fmt.Errorf("malformed rate limit %q", entry))
+			continue
+		}
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			errs = append(errs, fmt.Errorf("rate limit %q has an invalid limit", entry))
+			continue
+		}
+		window, err := time.ParseDuration(windowStr)
+		if err != nil || window <= 0 {
+			errs = append(errs, fmt.Errorf("rate limit %q has an invalid window", entry))
+			continue
+		}
+		rules[strings.TrimSpace(route)] = RateLimitRule{Limit: limit, Window: window}
 	}
-	return defaultValue
+	return rules, errors.Join(errs...)
 }
 
-func Load() *Config {
-	return &Config{
-		DynamoDBTable:        getEnv("COUNTERS_TABLE", ""),
-		SessionTable:         getEnv("SESSION_TABLE", ""),
-		RecaptchaSecretKey:   getEnv("RECAPTCHA_SECRET_KEY", ""),
-		SESRegion:            getEnv("SES_REGION", ""),
-		NotificationDstEmail: getEnv("NOTIFICATION_DST_EMAIL", "tinvuong2003@gmail.com"),
-		NotificationSrcEmail: getEnv("NOTIFICATION_SRC_EMAIL", ""),
+// Load reads the configuration from, by increasing precedence: defaults, the YAML or TOML
+// file named by --config or CONFIG_FILE, environment variables and the --key=value flags in
+// args. Settings are named after their environment variables. The returned error lists every
+// invalid, unknown or missing setting; the configuration is returned even then.
+func Load(args []string) (*Config, error) {
+	cfg, err := Parse(args)
+	return cfg, errors.Join(err, cfg.Validate())
+}
+
+// Parse is Load without checking that settings are complete and consistent, for commands
+// that don't need every setting
+func Parse(args []string) (*Config, error) {
+	l := newLoader(args)
+	csrfSecret := l.secret("CSRF_SECRET", "")
+	legacyCaptchaSecret, _, _ := l.lookup("RECAPTCHA_SECRET_KEY")
+
+	cfg := &Config{
+		DynamoDBTable:        l.string("COUNTERS_TABLE", ""),
+		SessionTable:         l.string("SESSION_TABLE", ""),
+		InboxTable:           l.string("INBOX_TABLE", ""),
+		OutboxTable:          l.string("OUTBOX_TABLE", ""),
+		OutboxMaxAttempts:    l.int("OUTBOX_MAX_ATTEMPTS", 5),
+		OutboxBaseDelay:      l.duration("OUTBOX_BASE_DELAY", 30*time.Second),
+		OutboxMaxDelay:       l.duration("OUTBOX_MAX_DELAY", time.Hour),
+		BlocklistTable:       l.string("BLOCKLIST_TABLE", ""),
+		BlocklistCacheTTL:    l.duration("BLOCKLIST_CACHE_TTL", time.Minute),
+		SESRegion:            l.string("SES_REGION", ""),
+		NotificationDstEmail: l.string("NOTIFICATION_DST_EMAIL", ""),
+		NotificationSrcEmail: l.string("NOTIFICATION_SRC_EMAIL", ""),
+
+		NotificationPhoneNumber:  l.string("NOTIFICATION_DST_PHONE", ""),
+		NotificationTimeout:      l.duration("NOTIFICATION_TIMEOUT", 5*time.Second),
+		NotificationTimezone:     l.string("NOTIFICATION_TIMEZONE", "America/New_York"),
+		QuietHours:               l.quietHours("QUIET_HOURS", ""),
+		LikeNotificationMode:     l.string("LIKE_NOTIFICATION_MODE", "immediate"),
+		DigestSkipEmpty:          l.bool("DIGEST_SKIP_EMPTY", true),
+		EmailNotificationTypes:   l.list("EMAIL_NOTIFICATION_TYPES", "like,contact,digest"),
+		SMSNotificationTypes:     l.list("SMS_NOTIFICATION_TYPES", "contact"),
+		SlackWebhookURL:          l.secret("SLACK_WEBHOOK_URL", ""),
+		SlackNotificationTypes:   l.list("SLACK_NOTIFICATION_TYPES", "like,contact,digest"),
+		DiscordWebhookURL:        l.secret("DISCORD_WEBHOOK_URL", ""),
+		DiscordNotificationTypes: l.list("DISCORD_NOTIFICATION_TYPES", "like,contact,digest"),
+		WebhookURL:               l.secret("WEBHOOK_URL", ""),
+		WebhookSecret:            l.secret("WEBHOOK_SECRET", ""),
+		WebhookNotificationTypes: l.list("WEBHOOK_NOTIFICATION_TYPES", "like,contact,digest"),
+		SMSDefaultCountryCode:    l.string("SMS_DEFAULT_COUNTRY_CODE", "1"),
+		SMSSenderID:              l.string("SMS_SENDER_ID", ""),
+		AutoReplyEnabled:         l.bool("AUTO_REPLY_ENABLED", false),
+		AutoReplySubject:         l.string("AUTO_REPLY_SUBJECT", "Thanks for reaching out!"),
+		EmailTemplateDir:         l.string("EMAIL_TEMPLATE_DIR", ""),
+		SiteURL:                  l.string("SITE_URL", "https://www.pwnph0fun.com"),
+		Environment:              l.string("ENVIRONMENT", "dev"),
+		LogLevel:                 l.string("LOG_LEVEL", ""),
+		TracingExporter:          l.string("TRACING_EXPORTER", "none"),
+		TracingEndpoint:          l.string("TRACING_ENDPOINT", ""),
+		CSRFSecret:               csrfSecret,
+		AdminAPIToken:            l.secret("ADMIN_API_TOKEN", ""),
+		SecretsFile:              l.string("SECRETS_FILE", ""),
+		SecretsRefreshInterval:   l.duration("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+
+		CaptchaProvider:         l.string("CAPTCHA_PROVIDER", "recaptcha_v3"),
+		CaptchaSecretKey:        l.secret("CAPTCHA_SECRET_KEY", legacyCaptchaSecret),
+		CaptchaMinScore:         l.float("CAPTCHA_MIN_SCORE", 0.5),
+		CaptchaExpectedAction:   l.string("CAPTCHA_EXPECTED_ACTION", "contact"),
+		CaptchaExpectedHostname: l.string("CAPTCHA_EXPECTED_HOSTNAME", "www.pwnph0fun.com"),
+		CaptchaMaxAge:           l.duration("CAPTCHA_MAX_AGE", 5*time.Minute),
+		CaptchaVerifyURL:        l.string("CAPTCHA_VERIFY_URL", ""),
+		CaptchaTimeout:          l.duration("CAPTCHA_TIMEOUT", 5*time.Second),
+
+		RateLimitTable: l.string("RATE_LIMIT_TABLE", ""),
+		RateLimits:     l.rateLimits("RATE_LIMITS", defaultRateLimits),
+
+		FormTokenSecret:     l.secret("FORM_TOKEN_SECRET", csrfSecret),
+		SpamThreshold:       l.float("SPAM_THRESHOLD", 5),
+		SpamMinFillTime:     l.duration("SPAM_MIN_FILL_TIME", 3*time.Second),
+		SpamMaxFormAge:      l.duration("SPAM_MAX_FORM_AGE", time.Hour),
+		SpamMaxLinks:        l.int("SPAM_MAX_LINKS", 2),
+		SpamBlockedKeywords: l.list("SPAM_BLOCKED_KEYWORDS", ""),
+		SpamBlockedPatterns: l.list("SPAM_BLOCKED_PATTERNS", ""),
+
+		BotIPRangesFile:  l.string("BOT_IP_RANGES_FILE", ""),
+		BotMinSessionAge: l.duration("BOT_MIN_SESSION_AGE", 50*time.Millisecond),
+
+		Jobs: l.jobs(map[string]JobConfig{
+			"digest":          {Enabled: true, Timeout: time.Minute},
+			"counter_rollup":  {Enabled: true, Timeout: time.Minute},
+			"session_cleanup": {Enabled: false, Timeout: 5 * time.Minute, BatchSize: 1000},
+			"outbox_retry":    {Enabled: true, Timeout: 2 * time.Minute, BatchSize: 25},
+		}),
+
+		FeatureFlags:         l.featureFlags("FEATURE_FLAGS", defaultFeatureFlags),
+		FeatureFlagsDynamoDB: l.bool("FEATURE_FLAGS_DYNAMODB", false),
+		FeatureFlagsCacheTTL: l.duration("FEATURE_FLAGS_CACHE_TTL", time.Minute),
+	}
+
+	cfg.settings = l.settings
+	cfg.recordSecretRefs()
+	l.errs = append(l.errs, l.unknown()...)
+	return cfg, errors.Join(l.errs...)
+}
+
+// MissingRequired returns the environment variables of required settings that aren't set
+func (c *Config) MissingRequired() []string {
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"COUNTERS_TABLE", c.DynamoDBTable},
+		{"SESSION_TABLE", c.SessionTable},
+		{"INBOX_TABLE", c.InboxTable},
+		{"CAPTCHA_SECRET_KEY", c.CaptchaSecretKey},
+		{"CSRF_SECRET", c.CSRFSecret},
+		{"NOTIFICATION_SRC_EMAIL", c.NotificationSrcEmail},
+		{"NOTIFICATION_DST_EMAIL", c.NotificationDstEmail},
+	}
+
+	var missing []string
+	for _, setting := range required {
+		if setting.value == "" {
+			missing = append(missing, setting.key)
+		}
+	}
+	return missing
+}
 
-		NotificationPhoneNumber: getEnv("NOTIFICATION_DST_PHONE", "5139148401"),
-		Environment:             getEnv("ENVIRONMENT", "dev"),
+// Settings returns every setting in name order with the source of its value. Secrets that
+// are set are replaced by "***", unless they are references to SSM or Secrets Manager.
+func (c *Config) Settings() []Setting {
+	settings := slices.Clone(c.settings)
+	for i := range settings {
+		if _, isRef := ParseSecretRef(settings[i].Value); settings[i].Secret && settings[i].Value != "" && !isRef {
+			settings[i].Value = "***"
+		}
 	}
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Key, b.Key) })
+	return settings
 }
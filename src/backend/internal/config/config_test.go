@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// validSettings are flags for every required setting
+var validSettings = []string{
+	"--counters-table=counters",
+	"--session-table=sessions",
+	"--inbox-table=inbox",
+	"--captcha-secret-key=captcha-secret",
+	"--csrf-secret=csrf-secret",
+	"--notification-src-email=noreply@example.com",
+	"--notification-dst-email=owner@example.com",
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+counters_table: from-file
+session_table: from-file
+notification_timeout: 10s
+spam_blocked_keywords: [casino, crypto]
+job:
+  digest:
+    enabled: false
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SESSION_TABLE", "from-env")
+	t.Setenv("INBOX_TABLE", "from-env")
+
+	cfg, err := Load(append(validSettings[2:], "--inbox-table=from-flag"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "from-file", cfg.DynamoDBTable)
+	assert.Equal(t, "from-env", cfg.SessionTable)
+	assert.Equal(t, "from-flag", cfg.InboxTable)
+	assert.Equal(t, 10*time.Second, cfg.NotificationTimeout)
+	assert.Equal(t, []string{"casino", "crypto"}, cfg.SpamBlockedKeywords)
+	assert.False(t, cfg.Jobs["digest"].Enabled)
+	assert.True(t, cfg.Jobs["counter_rollup"].Enabled)
+	assert.Equal(t, "recaptcha_v3", cfg.CaptchaProvider)
+	assert.Equal(t, "immediate", cfg.LikeNotificationMode, "likes are only digested when a deployment opts in")
+	assert.Equal(t, RateLimitRule{Limit: 30, Window: time.Minute}, cfg.RateLimits["/api/admin/blocklist"])
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+counters_table = "counters"
+quiet_hours = "22:00-07:00"
+
+[job.session_cleanup]
+batch_size = 50
+`)
+
+	cfg, err := Load(append(validSettings[1:], "--config="+path))
+	assert.NoError(t, err)
+	assert.Equal(t, "counters", cfg.DynamoDBTable)
+	assert.Equal(t, QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}, cfg.QuietHours)
+	assert.Equal(t, 50, cfg.Jobs["session_cleanup"].BatchSize)
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "counters_tabel: typo\n")
+
+	_, err := Load([]string{
+		"--config=" + path,
+		"--captcha-min-score=2",
+		"--notification-timeout=soon",
+		"--rate-limits=/api/contact=five/1h",
+		"--like-notification-mode=weekly",
+		"--spam-max-form-age=1s",
+		"--slack-webhook-url=hooks.slack.com/secret",
+		"not-a-flag",
+	})
+
+	assert.Error(t, err)
+	for _, expected := range []string{
+		`invalid flag "not-a-flag"`,
+		`NOTIFICATION_TIMEOUT: invalid duration "soon"`,
+		`RATE_LIMITS: rate limit "/api/contact=five/1h" has an invalid limit`,
+		"COUNTERS_TABEL: unknown setting in file",
+		"COUNTERS_TABLE: required",
+		"NOTIFICATION_DST_EMAIL: required",
+		"CSRF_SECRET: required",
+		"CAPTCHA_MIN_SCORE: 2 isn't between 0 and 1",
+		`LIKE_NOTIFICATION_MODE: "weekly" isn't one of immediate, digest`,
+		"SPAM_MAX_FORM_AGE: must be longer than SPAM_MIN_FILL_TIME",
+		"SLACK_WEBHOOK_URL: must be an absolute http or https URL",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+	assert.NotContains(t, err.Error(), "hooks.slack.com/secret")
+}
+
+func TestLoad_AutoReplyRequiresRateLimit(t *testing.T) {
+	_, err := Load(append(validSettings, "--auto-reply-enabled=true"))
+	assert.ErrorContains(t, err, "AUTO_REPLY_ENABLED: requires RATE_LIMIT_TABLE")
+
+	_, err = Load(append(validSettings, "--auto-reply-enabled=true", "--rate-limit-table=limits", "--rate-limits=/api/contact=5/1h"))
+	assert.ErrorContains(t, err, "AUTO_REPLY_ENABLED: requires an auto_reply rule in RATE_LIMITS")
+
+	_, err = Load(append(validSettings, "--auto-reply-enabled=true", "--rate-limit-table=limits"))
+	assert.NoError(t, err)
+}
+
+func TestLoad_JobBatchSize(t *testing.T) {
+	_, err := Load(append(validSettings, "--job-outbox-retry-batch-size=0", "--job-session-cleanup-enabled=true", "--job-session-cleanup-batch-size=0"))
+	assert.ErrorContains(t, err, "JOB_OUTBOX_RETRY_BATCH_SIZE: must be at least 1")
+	assert.ErrorContains(t, err, "JOB_SESSION_CLEANUP_BATCH_SIZE: must be at least 1")
+
+	// Disabled jobs and jobs without batches don't need a batch size
+	_, err = Load(append(validSettings, "--job-session-cleanup-batch-size=0", "--job-digest-batch-size=0"))
+	assert.NoError(t, err)
+}
+
+func TestLoad_FeatureFlags(t *testing.T) {
+	cfg, err := Load(append(validSettings, "--feature-flags=likes=off, bot_visits=25%"))
+	assert.NoError(t, err)
+	assert.Equal(t, FeatureFlag{Rollout: 0}, cfg.FeatureFlags["likes"])
+	assert.Equal(t, FeatureFlag{Rollout: 25}, cfg.FeatureFlags["bot_visits"])
+	assert.Equal(t, FeatureFlag{Rollout: 100}, cfg.FeatureFlags["contact"])
+
+	_, err = Load(append(validSettings, "--feature-flags=likes=maybe,comments=on,sms=150%"))
+	assert.ErrorContains(t, err, `FEATURE_FLAGS: invalid feature flag "maybe"`)
+	assert.ErrorContains(t, err, `unknown feature flag "comments"`)
+	assert.ErrorContains(t, err, `invalid feature flag "150%"`)
+}
+
+func TestConfig_Settings(t *testing.T) {
+	t.Setenv("ADMIN_API_TOKEN", "admin-token")
+	t.Setenv("WEBHOOK_URL", "https://example.com/hooks/token")
+
+	cfg, err := Parse([]string{"--counters-table=counters"})
+	assert.NoError(t, err)
+
+	settings := map[string]Setting{}
+	for _, setting := range cfg.Settings() {
+		settings[setting.Key] = setting
+	}
+	assert.Equal(t, Setting{Key: "ADMIN_API_TOKEN", Value: "***", Source: SourceEnv, Secret: true}, settings["ADMIN_API_TOKEN"])
+	assert.Equal(t, Setting{Key: "COUNTERS_TABLE", Value: "counters", Source: SourceFlag}, settings["COUNTERS_TABLE"])
+	assert.Equal(t, Setting{Key: "WEBHOOK_SECRET", Source: SourceDefault, Secret: true}, settings["WEBHOOK_SECRET"])
+	assert.Equal(t, Setting{Key: "WEBHOOK_URL", Value: "***", Source: SourceEnv, Secret: true}, settings["WEBHOOK_URL"])
+	assert.Equal(t, Setting{Key: "OUTBOX_BASE_DELAY", Value: "30s", Source: SourceDefault}, settings["OUTBOX_BASE_DELAY"])
+	assert.Equal(t, "admin-token", cfg.AdminAPIToken)
+}
+
+func TestSplitFlags(t *testing.T) {
+	flags, rest := SplitFlags([]string{"--environment=prod", "digest", "--extra"})
+	assert.Equal(t, []string{"--environment=prod"}, flags)
+	assert.Equal(t, []string{"digest", "--extra"}, rest)
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting, from lowest to highest precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// configFileKey names the YAML or TOML file read before the environment
+const configFileKey = "CONFIG_FILE"
+
+// Setting is the effective value of one setting and where it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	Secret bool
+}
+
+// loader resolves settings from flags, the environment, the config file and defaults,
+// recording each one and collecting every invalid value instead of stopping at the first
+type loader struct {
+	file     map[string]string
+	flags    map[string]string
+	used     map[string]bool
+	settings []Setting
+	errs     []error
+}
+
+func newLoader(args []string) *loader {
+	l := &loader{file: map[string]string{}, flags: map[string]string{}, used: map[string]bool{}}
+	l.parseFlags(args)
+
+	path, source, ok := l.lookup(configFileKey)
+	if ok {
+		l.settings = append(l.settings, Setting{Key: configFileKey, Value: path, Source: source})
+		if err := l.readFile(path); err != nil {
+			l.errs = append(l.errs, err)
+		}
+	}
+	return l
+}
+
+// parseFlags reads --key=value arguments. Keys are setting names in any case, with dashes
+// for underscores, e.g. --counters-table=counters. --config names the config file.
+func (l *loader) parseFlags(args []string) {
+	for _, arg := range args {
+		name, value, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || !ok || name == "" {
+			l.errs = append(l.errs, fmt.Errorf("invalid flag %q, expected --key=value", arg))
+			continue
+		}
+		key := flagKey(name)
+		if key == "CONFIG" {
+			key = configFileKey
+		}
+		l.flags[key] = value
+	}
+}
+
+func flagKey(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// readFile reads a YAML or TOML file. Keys are setting names in any case, and nested tables
+// join their keys with underscores, so job.digest.enabled sets JOB_DIGEST_ENABLED. Lists are
+// joined with commas.
+func (l *loader) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", configFileKey, err)
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("%s: unsupported format %q, use .yaml, .yml or .toml", configFileKey, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to parse %s: %w", configFileKey, path, err)
+	}
+
+	flatten("", values, l.file)
+	return nil
+}
+
+func flatten(prefix string, values map[string]any, into map[string]string) {
+	for name, value := range values {
+		key := strings.ToUpper(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, into)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			into[key] = strings.Join(items, ",")
+		default:
+			into[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// lookup returns the value of key from the highest precedence source that sets it
+func (l *loader) lookup(key string) (string, string, bool) {
+	l.used[key] = true
+	if value, ok := l.flags[key]; ok {
+		return value, SourceFlag, true
+	}
+	if value := os.Getenv(key); value != "" {
+		return value, SourceEnv, true
+	}
+	if value, ok := l.file[key]; ok {
+		return value, SourceFile, true
+	}
+	return "", "", false
+}
+
+// unknown returns an error for each key of the file or flags that isn't a setting
+func (l *loader) unknown() []error {
+	var errs []error
+	for _, source := range []struct {
+		name   string
+		values map[string]string
+	}{{SourceFile, l.file}, {SourceFlag, l.flags}} {
+		var keys []string
+		for key := range source.values {
+			if !l.used[key] {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			errs = append(errs, fmt.Errorf("%s: unknown setting in %s", key, source.name))
+		}
+	}
+	return errs
+}
+
+// get resolves a typed setting. Values that don't parse are reported and replaced by the default.
+func get[T any](l *loader, key string, defaultValue T, secret bool, format func(T) string, parse func(string) (T, error)) T {
+	value, source, ok := l.lookup(key)
+	if !ok {
+		l.settings = append(l.settings, Setting{Key: key, Value: format(defaultValue), Source: SourceDefault, Secret: secret})
+		return defaultValue
+	}
+
+	parsed, err := parse(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %w", key, err))
+		l.settings = append(l.settings, Setting{Key: key, Value: format(defaultValue), Source: SourceDefault, Secret: secret})
+		return defaultValue
+	}
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: source, Secret: secret})
+	return parsed
+}
+
+func identity(s string) string { return s }
+
+func (l *loader) string(key, defaultValue string) string {
+	return get(l, key, defaultValue, false, identity, func(s string) (string, error) { return s, nil })
+}
+
+// secret is a string setting that config print redacts
+func (l *loader) secret(key, defaultValue string) string {
+	return get(l, key, defaultValue, true, identity, func(s string) (string, error) { return s, nil })
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	return get(l, key, defaultValue, false, strconv.Itoa, func(s string) (int, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", s)
+		}
+		return i, nil
+	})
+}
+
+func (l *loader) float(key string, defaultValue float64) float64 {
+	return get(l, key, defaultValue, false, func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }, func(s string) (float64, error) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		return f, nil
+	})
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	return get(l, key, defaultValue, false, strconv.FormatBool, func(s string) (bool, error) {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean %q", s)
+		}
+		return b, nil
+	})
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	return get(l, key, defaultValue, false, time.Duration.String, func(s string) (time.Duration, error) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return d, nil
+	})
+}
+
+// list splits a comma-separated value, dropping empty entries
+func (l *loader) list(key, defaultValue string) []string {
+	return get(l, key, splitList(defaultValue), false, func(list []string) string { return strings.Join(list, ",") },
+		func(s string) ([]string, error) { return splitList(s), nil })
+}
+
+func splitList(value string) []string {
+	var list []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (l *loader) quietHours(key, defaultValue string) QuietHours {
+	defaultHours, _ := parseQuietHours(defaultValue)
+	return get(l, key, defaultHours, false, func(QuietHours) string { return defaultValue }, parseQuietHours)
+}
+
+func (l *loader) rateLimits(key, defaultValue string) map[string]RateLimitRule {
+	defaultRules, _ := parseRateLimits(defaultValue)
+	return get(l, key, defaultRules, false, func(map[string]RateLimitRule) string { return defaultValue }, parseRateLimits)
+}
+
+// featureFlags reads flags over the defaults, so only the flags that change need to be listed.
+// Flags that aren't in the defaults are reported.
+func (l *loader) featureFlags(key, defaultValue string) map[string]FeatureFlag {
+	defaultFlags, _ := parseFeatureFlags(defaultValue)
+	return get(l, key, defaultFlags, false, func(map[string]FeatureFlag) string { return defaultValue }, func(s string) (map[string]FeatureFlag, error) {
+		flags, err := parseFeatureFlags(s)
+		errs := []error{err}
+		merged := maps.Clone(defaultFlags)
+		for name, flag := range flags {
+			if _, ok := defaultFlags[name]; !ok {
+				errs = append(errs, fmt.Errorf("unknown feature flag %q", name))
+				continue
+			}
+			merged[name] = flag
+		}
+		return merged, errors.Join(errs...)
+	})
+}
+
+// jobs reads JOB_<NAME>_ENABLED, JOB_<NAME>_TIMEOUT and JOB_<NAME>_BATCH_SIZE for each job
+func (l *loader) jobs(defaults map[string]JobConfig) map[string]JobConfig {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	jobs := make(map[string]JobConfig, len(defaults))
+	for _, name := range names {
+		job := defaults[name]
+		prefix := "JOB_" + strings.ToUpper(name) + "_"
+		jobs[name] = JobConfig{
+			Enabled:   l.bool(prefix+"ENABLED", job.Enabled),
+			Timeout:   l.duration(prefix+"TIMEOUT", job.Timeout),
+			BatchSize: l.int(prefix+"BATCH_SIZE", job.BatchSize),
+		}
+	}
+	return jobs
+}
+
+// SplitFlags separates the leading --key=value arguments of a command from the rest
+func SplitFlags(args []string) (flags, rest []string) {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret reference schemes
+const (
+	SecretSchemeSSM            = "ssm"
+	SecretSchemeSecretsManager = "secretsmanager"
+)
+
+// SecretRef points to a secret stored outside the configuration, written as
+// ssm:/path/to/parameter or secretsmanager:name, optionally followed by #key to pick a field
+// of a JSON secret, e.g. secretsmanager:resume/keys#recaptcha
+type SecretRef struct {
+	Scheme string
+	Name   string
+	Key    string
+}
+
+// ParseSecretRef parses a secret reference. ok is false for plain values.
+func ParseSecretRef(value string) (ref SecretRef, ok bool) {
+	scheme, rest, found := strings.Cut(value, ":")
+	if !found || (scheme != SecretSchemeSSM && scheme != SecretSchemeSecretsManager) || rest == "" {
+		return SecretRef{}, false
+	}
+	name, key, _ := strings.Cut(rest, "#")
+	return SecretRef{Scheme: scheme, Name: name, Key: key}, name != ""
+}
+
+func (r SecretRef) String() string {
+	s := r.Scheme + ":" + r.Name
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// SecretProvider fetches the raw value of a secret by name
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+type cachedSecret struct {
+	value     string
+	err       error     // why the secret couldn't be fetched, while it never was
+	fetchedAt time.Time // last fetch attempt, successful or not
+}
+
+// SecretResolver resolves secret references with a provider per scheme and caches the raw
+// secrets for the refresh interval. Failed fetches are cached too, so an outage of the
+// provider costs one attempt per secret and interval.
+type SecretResolver struct {
+	providers map[string]SecretProvider
+	refresh   time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+func NewSecretResolver(refresh time.Duration, providers map[string]SecretProvider) *SecretResolver {
+	return &SecretResolver{
+		providers: providers,
+		refresh:   refresh,
+		now:       time.Now,
+		cache:     map[string]cachedSecret{},
+	}
+}
+
+// Resolve returns the secret ref points to, fetching it when it isn't cached or was last
+// fetched longer than the refresh interval ago. When a refresh fails, the last value is
+// returned until the next attempt.
+func (r *SecretResolver) Resolve(ctx context.Context, ref SecretRef) (string, error) {
+	cacheKey := ref.Scheme + ":" + ref.Name
+
+	r.mu.Lock()
+	cached, ok := r.cache[cacheKey]
+	r.mu.Unlock()
+
+	if !ok || r.now().Sub(cached.fetchedAt) >= r.refresh {
+		provider, found := r.providers[ref.Scheme]
+		if !found {
+			return "", fmt.Errorf("no provider for %s secrets", ref.Scheme)
+		}
+		fetched, err := provider.GetSecret(ctx, ref.Name)
+		if err != nil {
+			err = fmt.Errorf("failed to get %s: %w", ref, err)
+			if !ok || cached.err != nil {
+				cached.err = err
+			} else {
+				slog.WarnContext(ctx, "Couldn't refresh secret, keeping the previous value", "secret", cacheKey, "error", err)
+			}
+		} else {
+			cached = cachedSecret{value: fetched}
+		}
+		cached.fetchedAt = r.now()
+
+		r.mu.Lock()
+		r.cache[cacheKey] = cached
+		r.mu.Unlock()
+	}
+	if cached.err != nil {
+		return "", cached.err
+	}
+
+	value := cached.value
+
+	if ref.Key == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("%s isn't a JSON object", ref)
+	}
+	field, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("%s: no key %q", ref, ref.Key)
+	}
+	if s, ok := field.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(field), nil
+}
+
+// secretFields are the settings whose values may be secret references
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"CAPTCHA_SECRET_KEY":  &c.CaptchaSecretKey,
+		"CSRF_SECRET":         &c.CSRFSecret,
+		"FORM_TOKEN_SECRET":   &c.FormTokenSecret,
+		"ADMIN_API_TOKEN":     &c.AdminAPIToken,
+		"WEBHOOK_SECRET":      &c.WebhookSecret,
+		"SLACK_WEBHOOK_URL":   &c.SlackWebhookURL,
+		"DISCORD_WEBHOOK_URL": &c.DiscordWebhookURL,
+		"WEBHOOK_URL":         &c.WebhookURL,
+	}
+}
+
+// recordSecretRefs remembers which secret settings are references, so they can be resolved
+// again when the secrets are refreshed
+func (c *Config) recordSecretRefs() {
+	c.secretRefs = map[string]SecretRef{}
+	for key, field := range c.secretFields() {
+		if ref, ok := ParseSecretRef(*field); ok {
+			c.secretRefs[key] = ref
+		}
+	}
+}
+
+// ResolveSecrets replaces the secret references of the configuration by the secrets they
+// point to, and keeps resolver for the accessors returned by Secret. Every reference is
+// resolved even if some fail, and the failures are returned together.
+func (c *Config) ResolveSecrets(ctx context.Context, resolver *SecretResolver) error {
+	c.secretResolver = resolver
+	fields := c.secretFields()
+	var errs []error
+	for key, ref := range c.secretRefs {
+		value, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		*fields[key] = value
+	}
+	return errors.Join(errs...)
+}
+
+// Secret returns the current value of a secret setting
+type Secret func(ctx context.Context) string
+
+// StaticSecret is a Secret that never changes
+func StaticSecret(value string) Secret {
+	return func(ctx context.Context) string { return value }
+}
+
+// Secret returns an accessor for the secret setting key, for services to read the secret
+// when they use it instead of copying it when they are created. References are resolved
+// through the SecretResolver on each call, so rotated secrets are picked up once its cache
+// expires. The last value is kept while a secret can't be fetched.
+func (c *Config) Secret(key string) Secret {
+	field, ok := c.secretFields()[key]
+	if !ok {
+		panic("config: " + key + " isn't a secret setting")
+	}
+	ref, isRef := c.secretRefs[key]
+	if !isRef || c.secretResolver == nil {
+		return func(ctx context.Context) string { return *field }
+	}
+
+	resolver := c.secretResolver
+	var mu sync.Mutex
+	last := *field
+	return func(ctx context.Context) string {
+		value, err := resolver.Resolve(ctx, ref)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			slog.WarnContext(ctx, "Couldn't refresh secret, keeping the previous value", "setting", key, "error", err)
+			return last
+		}
+		last = value
+		return value
+	}
+}
+
+// unresolvedSecret reports whether value is a secret reference that hasn't been resolved yet
+func (c *Config) unresolvedSecret(key, value string) bool {
+	ref, ok := c.secretRefs[key]
+	return ok && ref.String() == value
+}
+
+// SSMGetter is the part of the SSM client the SSM provider uses
+type SSMGetter interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// SSMProvider reads SecureString or String parameters from SSM Parameter Store
+type SSMProvider struct {
+	client SSMGetter
+}
+
+func NewSSMProvider(client SSMGetter) *SSMProvider {
+	return &SSMProvider{client: client}
+}
+
+func (p *SSMProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	withDecryption := true
+	response, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{Name: &name, WithDecryption: &withDecryption})
+	if err != nil {
+		return "", err
+	}
+	if response.Parameter == nil || response.Parameter.Value == nil {
+		return "", errors.New("parameter has no value")
+	}
+	return *response.Parameter.Value, nil
+}
+
+// SecretsManagerGetter is the part of the Secrets Manager client the provider uses
+type SecretsManagerGetter interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerProvider reads the current version of string secrets from Secrets Manager
+type SecretsManagerProvider struct {
+	client SecretsManagerGetter
+}
+
+func NewSecretsManagerProvider(client SecretsManagerGetter) *SecretsManagerProvider {
+	return &SecretsManagerProvider{client: client}
+}
+
+func (p *SecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	response, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &name})
+	if err != nil {
+		return "", err
+	}
+	if response.SecretString == nil {
+		return "", errors.New("secret has no string value")
+	}
+	return *response.SecretString, nil
+}
+
+// FileSecrets stands in for SSM and Secrets Manager during development and tests. It reads a
+// YAML or JSON file mapping references without their #key to values; values that are
+// objects are served as JSON, so #key references work as with Secrets Manager:
+//
+//	ssm:/resume/recaptcha: local-secret
+//	secretsmanager:resume/keys:
+//	  recaptcha: local-secret
+type FileSecrets struct {
+	scheme  string
+	secrets map[string]string
+}
+
+// LoadFileSecrets reads the file and returns a provider for each scheme
+func LoadFileSecrets(path string) (map[string]SecretProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+		// JSON is a subset of YAML
+		err = yaml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported secrets file format %q, use .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	secrets := map[string]string{}
+	for ref, value := range values {
+		switch v := value.(type) {
+		case map[string]any:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ref, err)
+			}
+			secrets[ref] = string(encoded)
+		default:
+			secrets[ref] = fmt.Sprint(v)
+		}
+	}
+
+	return map[string]SecretProvider{
+		SecretSchemeSSM:            &FileSecrets{scheme: SecretSchemeSSM, secrets: secrets},
+		SecretSchemeSecretsManager: &FileSecrets{scheme: SecretSchemeSecretsManager, secrets: secrets},
+	}, nil
+}
+
+func (f *FileSecrets) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := f.secrets[f.scheme+":"+name]
+	if !ok {
+		return "", fmt.Errorf("%s:%s isn't in the secrets file", f.scheme, name)
+	}
+	return value, nil
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingProvider serves secrets from a map and counts fetches
+type countingProvider struct {
+	secrets map[string]string
+	calls   int
+	err     error
+}
+
+func (p *countingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	value, ok := p.secrets[name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected SecretRef
+		ok       bool
+	}{
+		{"ssm:/resume/recaptcha", SecretRef{Scheme: "ssm", Name: "/resume/recaptcha"}, true},
+		{"secretsmanager:resume/keys#recaptcha", SecretRef{Scheme: "secretsmanager", Name: "resume/keys", Key: "recaptcha"}, true},
+		{"plain-secret", SecretRef{}, false},
+		{"https://hooks.slack.com/services/x", SecretRef{}, false},
+		{"ssm:", SecretRef{}, false},
+		{"secretsmanager:#key", SecretRef{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, ok := ParseSecretRef(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, ref)
+				assert.Equal(t, tt.value, ref.String())
+			}
+		})
+	}
+}
+
+func TestSecretResolver_CachesForRefreshInterval(t *testing.T) {
+	provider := &countingProvider{secrets: map[string]string{"resume/keys": `{"recaptcha":"captcha-secret","admin":"admin-token"}`}}
+	resolver := NewSecretResolver(5*time.Minute, map[string]SecretProvider{SecretSchemeSecretsManager: provider})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "resume/keys", Key: "recaptcha"})
+	assert.NoError(t, err)
+	assert.Equal(t, "captcha-secret", value)
+
+	// Another key of the same secret comes from the cache
+	value, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "resume/keys", Key: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-token", value)
+	assert.Equal(t, 1, provider.calls)
+
+	provider.secrets["resume/keys"] = `{"recaptcha":"rotated"}`
+	now = now.Add(5 * time.Minute)
+	value, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "resume/keys", Key: "recaptcha"})
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+	assert.Equal(t, 2, provider.calls)
+}
+
+func TestSecretResolver_FailedFetchIsCached(t *testing.T) {
+	provider := &countingProvider{secrets: map[string]string{"/resume/recaptcha": "captcha-secret"}}
+	resolver := NewSecretResolver(5*time.Minute, map[string]SecretProvider{SecretSchemeSSM: provider})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+	ctx := context.Background()
+	ref := SecretRef{Scheme: SecretSchemeSSM, Name: "/resume/recaptcha"}
+
+	_, err := resolver.Resolve(ctx, ref)
+	assert.NoError(t, err)
+
+	// A failed refresh serves the last value, and isn't retried before the refresh interval
+	now = now.Add(5 * time.Minute)
+	provider.err = errors.New("throttled")
+	for i := 0; i < 3; i++ {
+		value, err := resolver.Resolve(ctx, ref)
+		assert.NoError(t, err)
+		assert.Equal(t, "captcha-secret", value)
+		now = now.Add(time.Minute)
+	}
+	assert.Equal(t, 2, provider.calls)
+
+	// Secrets that were never fetched return the cached error
+	missing := SecretRef{Scheme: SecretSchemeSSM, Name: "/resume/missing"}
+	for i := 0; i < 3; i++ {
+		_, err := resolver.Resolve(ctx, missing)
+		assert.EqualError(t, err, "failed to get ssm:/resume/missing: throttled")
+	}
+	assert.Equal(t, 3, provider.calls)
+
+	now = now.Add(5 * time.Minute)
+	provider.err = nil
+	provider.secrets["/resume/recaptcha"] = "rotated"
+	value, err := resolver.Resolve(ctx, ref)
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+	assert.Equal(t, 4, provider.calls)
+}
+
+func TestSecretResolver_Errors(t *testing.T) {
+	provider := &countingProvider{secrets: map[string]string{"/resume/plain": "not-json"}}
+	resolver := NewSecretResolver(time.Minute, map[string]SecretProvider{SecretSchemeSSM: provider})
+	ctx := context.Background()
+
+	_, err := resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "resume/keys"})
+	assert.EqualError(t, err, "no provider for secretsmanager secrets")
+
+	_, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSSM, Name: "/resume/plain", Key: "key"})
+	assert.EqualError(t, err, "ssm:/resume/plain#key isn't a JSON object")
+
+	_, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSSM, Name: "/resume/missing"})
+	assert.EqualError(t, err, "failed to get ssm:/resume/missing: not found")
+}
+
+func TestLoadFileSecrets(t *testing.T) {
+	path := writeConfigFile(t, "secrets.yaml", `
+ssm:/resume/recaptcha: local-captcha
+secretsmanager:resume/keys:
+  admin: local-admin
+`)
+
+	providers, err := LoadFileSecrets(path)
+	assert.NoError(t, err)
+	resolver := NewSecretResolver(time.Minute, providers)
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSSM, Name: "/resume/recaptcha"})
+	assert.NoError(t, err)
+	assert.Equal(t, "local-captcha", value)
+
+	value, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "resume/keys", Key: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "local-admin", value)
+
+	// References only match the scheme they were written with
+	_, err = resolver.Resolve(ctx, SecretRef{Scheme: SecretSchemeSecretsManager, Name: "/resume/recaptcha"})
+	assert.Error(t, err)
+
+	_, err = LoadFileSecrets(writeConfigFile(t, "secrets.toml", ""))
+	assert.ErrorContains(t, err, "unsupported secrets file format")
+}
+
+func TestConfig_ResolveSecrets(t *testing.T) {
+	cfg, err := Parse(append(validSettings,
+		"--captcha-secret-key=ssm:/resume/recaptcha",
+		"--slack-webhook-url=secretsmanager:resume/keys#slack",
+	))
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Validate(), "unresolved references aren't validated as URLs")
+
+	ssmProvider := &countingProvider{secrets: map[string]string{"/resume/recaptcha": "captcha-secret"}}
+	smProvider := &countingProvider{secrets: map[string]string{"resume/keys": `{"slack":"https://hooks.slack.com/services/x"}`}}
+	resolver := NewSecretResolver(time.Minute, map[string]SecretProvider{
+		SecretSchemeSSM:            ssmProvider,
+		SecretSchemeSecretsManager: smProvider,
+	})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, cfg.ResolveSecrets(ctx, resolver))
+	assert.Equal(t, "captcha-secret", cfg.CaptchaSecretKey)
+	assert.Equal(t, "https://hooks.slack.com/services/x", cfg.SlackWebhookURL)
+
+	captchaSecret := cfg.Secret("CAPTCHA_SECRET_KEY")
+	slackURL := cfg.Secret("SLACK_WEBHOOK_URL")
+	adminToken := cfg.Secret("ADMIN_API_TOKEN")
+	assert.Equal(t, "captcha-secret", captchaSecret(ctx))
+	assert.Equal(t, "", adminToken(ctx), "plain settings are read as is")
+	assert.Equal(t, 1, ssmProvider.calls, "secrets come from the cache until the refresh interval")
+
+	// Rotated secrets are picked up once the cache expires, and a failed refresh keeps the last value
+	now = now.Add(time.Minute)
+	ssmProvider.err = errors.New("throttled")
+	smProvider.secrets["resume/keys"] = `{"slack":"https://hooks.slack.com/services/rotated"}`
+	assert.Equal(t, "captcha-secret", captchaSecret(ctx))
+	assert.Equal(t, "https://hooks.slack.com/services/rotated", slackURL(ctx))
+
+	// The failed fetch is only retried after the refresh interval
+	ssmProvider.err = nil
+	ssmProvider.secrets["/resume/recaptcha"] = "rotated-captcha-secret"
+	assert.Equal(t, "captcha-secret", captchaSecret(ctx))
+	now = now.Add(time.Minute)
+	assert.Equal(t, "rotated-captcha-secret", captchaSecret(ctx))
+
+	err = cfg.ResolveSecrets(ctx, &SecretResolver{})
+	assert.ErrorContains(t, err, "CAPTCHA_SECRET_KEY: no provider for ssm secrets")
+	assert.ErrorContains(t, err, "SLACK_WEBHOOK_URL: no provider for secretsmanager secrets")
+
+	// config print shows references as is
+	for _, setting := range cfg.Settings() {
+		if setting.Key == "CAPTCHA_SECRET_KEY" {
+			assert.Equal(t, "ssm:/resume/recaptcha", setting.Value)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// batchedJobs are the jobs that process at most BatchSize items per run
+var batchedJobs = map[string]bool{"outbox_retry": true, "session_cleanup": true}
+
+// Validate checks that required settings are set and that values are in range, and returns
+// every problem found at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	for _, key := range c.MissingRequired() {
+		fail(key, "required")
+	}
+
+	oneOf := func(key, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			fail(key, "%q isn't one of %s", value, strings.Join(allowed, ", "))
+		}
+	}
+	oneOf("CAPTCHA_PROVIDER", c.CaptchaProvider, "recaptcha_v2", "recaptcha_v3", "hcaptcha", "turnstile")
+	oneOf("LIKE_NOTIFICATION_MODE", c.LikeNotificationMode, "immediate", "digest")
+	oneOf("TRACING_EXPORTER", c.TracingExporter, "none", "otlp", "stdout")
+	if c.LogLevel != "" {
+		oneOf("LOG_LEVEL", strings.ToLower(c.LogLevel), "debug", "info", "warn", "error")
+	}
+	if c.Environment == "" {
+		fail("ENVIRONMENT", "required")
+	}
+
+	if c.CaptchaMinScore < 0 || c.CaptchaMinScore > 1 {
+		fail("CAPTCHA_MIN_SCORE", "%g isn't between 0 and 1", c.CaptchaMinScore)
+	}
+	if c.OutboxMaxAttempts < 1 {
+		fail("OUTBOX_MAX_ATTEMPTS", "must be at least 1")
+	}
+	if c.SecretsRefreshInterval <= 0 {
+		fail("SECRETS_REFRESH_INTERVAL", "must be positive")
+	}
+	if c.FeatureFlagsDynamoDB && c.FeatureFlagsCacheTTL <= 0 {
+		fail("FEATURE_FLAGS_CACHE_TTL", "must be positive")
+	}
+	// Auto-replies email an address the sender chooses, only the auto_reply limit keeps the
+	// contact form from being used to send mail to anyone
+	if c.AutoReplyEnabled {
+		if c.RateLimitTable == "" {
+			fail("AUTO_REPLY_ENABLED", "requires RATE_LIMIT_TABLE")
+		} else if _, ok := c.RateLimits["auto_reply"]; !ok {
+			fail("AUTO_REPLY_ENABLED", "requires an auto_reply rule in RATE_LIMITS")
+		}
+	}
+	if c.SpamMaxLinks < 0 {
+		fail("SPAM_MAX_LINKS", "can't be negative")
+	}
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"CAPTCHA_TIMEOUT", c.CaptchaTimeout},
+		{"CAPTCHA_MAX_AGE", c.CaptchaMaxAge},
+		{"NOTIFICATION_TIMEOUT", c.NotificationTimeout},
+		{"OUTBOX_BASE_DELAY", c.OutboxBaseDelay},
+		{"OUTBOX_MAX_DELAY", c.OutboxMaxDelay},
+		{"SPAM_MAX_FORM_AGE", c.SpamMaxFormAge},
+	} {
+		if d.value <= 0 {
+			fail(d.key, "must be positive")
+		}
+	}
+	if c.SpamMaxFormAge <= c.SpamMinFillTime {
+		fail("SPAM_MAX_FORM_AGE", "must be longer than SPAM_MIN_FILL_TIME")
+	}
+	if c.OutboxMaxDelay < c.OutboxBaseDelay {
+		fail("OUTBOX_MAX_DELAY", "is shorter than OUTBOX_BASE_DELAY")
+	}
+	for name, job := range c.Jobs {
+		key := "JOB_" + strings.ToUpper(name)
+		if job.Enabled && job.Timeout <= 0 {
+			fail(key+"_TIMEOUT", "must be positive")
+		}
+		if job.BatchSize < 0 {
+			fail(key+"_BATCH_SIZE", "can't be negative")
+		} else if job.Enabled && batchedJobs[name] && job.BatchSize < 1 {
+			fail(key+"_BATCH_SIZE", "must be at least 1")
+		}
+	}
+
+	if _, err := time.LoadLocation(c.NotificationTimezone); err != nil {
+		fail("NOTIFICATION_TIMEZONE", "unknown timezone %q", c.NotificationTimezone)
+	}
+	for _, e := range []struct {
+		key   string
+		value string
+	}{
+		{"NOTIFICATION_SRC_EMAIL", c.NotificationSrcEmail},
+		{"NOTIFICATION_DST_EMAIL", c.NotificationDstEmail},
+	} {
+		if e.value == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(e.value); err != nil {
+			fail(e.key, "invalid email address %q", e.value)
+		}
+	}
+
+	for _, u := range []struct {
+		key      string
+		value    string
+		required bool
+	}{
+		{"SITE_URL", c.SiteURL, true},
+		{"CAPTCHA_VERIFY_URL", c.CaptchaVerifyURL, false},
+		{"SLACK_WEBHOOK_URL", c.SlackWebhookURL, false},
+		{"DISCORD_WEBHOOK_URL", c.DiscordWebhookURL, false},
+		{"WEBHOOK_URL", c.WebhookURL, false},
+		{"TRACING_ENDPOINT", c.TracingEndpoint, false},
+	} {
+		if u.value == "" || c.unresolvedSecret(u.key, u.value) {
+			if u.required && u.value == "" {
+				fail(u.key, "required")
+			}
+			continue
+		}
+		// Webhook URLs hold credentials, so they aren't repeated in the error
+		if parsed, err := url.Parse(u.value); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			fail(u.key, "must be an absolute http or https URL")
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"main/internal/model"
+	"main/internal/service"
+	"main/internal/storage"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const errCodeUnauthorized = "unauthorized"
+
+func (h *APIHandler) adminHeaders(allowMethods string) map[string]string {
+	headers := corsHeaders(h.siteOrigin, allowMethods)
+	headers["Access-Control-Allow-Headers"] += ",Authorization"
+	return headers
+}
+
+type adminHandlerFunc func(ctx context.Context, req events.APIGatewayProxyRequest, headers map[string]string) (events.APIGatewayProxyResponse, error)
+
+// withAdmin restricts a route to callers presenting the admin bearer token
+func (h *APIHandler) withAdmin(allowMethods string, next adminHandlerFunc) sessionHandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
+		headers := h.adminHeaders(allowMethods)
+
+		// Handle CORS preflight request
+		if req.HTTPMethod == "OPTIONS" {
+			return events.APIGatewayProxyResponse{
+				StatusCode: 204,
+				Headers:    headers,
+				Body:       "",
+			}, nil
+		}
+
+		if !h.adminAuthService.Authorize(ctx, getHeader(req.Headers, "Authorization")) {
+			return h.codedErrorResponse(401, errCodeUnauthorized, "Unauthorized", headers), nil
+		}
+
+		return next(ctx, req, headers)
+	}
+}
+
+// handleAdminMessages lists inbox messages: GET /api/admin/messages?status=&from=&to=&limit=&cursor=
+func (h *APIHandler) handleAdminMessages(ctx context.Context, req events.APIGatewayProxyRequest, headers map[string]string) (events.APIGatewayProxyResponse, error) {
+	if req.HTTPMethod != "GET" {
+		return h.errorResponse(405, "Method not allowed", headers), nil
+	}
+
+	params := req.QueryStringParameters
+	from, err := parseTimeParam(params["from"], false)
+	if err != nil {
+		return h.errorResponse(400, "Invalid from date", headers), nil
+	}
+	to, err := parseTimeParam(params["to"], true)
+	if err != nil {
+		return h.errorResponse(400, "Invalid to date", headers), nil
+	}
+	limit := 0
+	if params["limit"] != "" {
+		limit, err = strconv.Atoi(params["limit"])
+		if err != nil {
+			return h.errorResponse(400, "Invalid limit", headers), nil
+		}
+	}
+
+	messages, nextCursor, err := h.inboxService.ListMessages(ctx, params["status"], from, to, limit, params["cursor"])
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, storage.ErrInvalidCursor) {
+			return h.errorResponse(400, err.Error(), headers), nil
+		}
+		slog.ErrorContext(ctx, "Error listing messages", "error", err)
+		return h.errorResponse(500, "Database error", headers), nil
+	}
+
+	data := map[string]any{"messages": messages}
+	if nextCursor != "" {
+		data["next_cursor"] = nextCursor
+	}
+	return h.dataResponse(200, data, headers), nil
+}
+
+// handleAdminMessage reads, updates the status of, or deletes a single inbox message
+func (h *APIHandler) handleAdminMessage(ctx context.Context, req events.APIGatewayProxyRequest, headers map[string]string) (events.APIGatewayProxyResponse, error) {
+	id := req.PathParameters["id"]
+	if id == "" {
+		return h.errorResponse(400, "Missing message ID", headers), nil
+	}
+
+	switch req.HTTPMethod {
+	case "GET":
+		msg, err := h.inboxService.GetMessage(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error getting message", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		if msg == nil {
+			return h.errorResponse(404, "Message not found", headers), nil
+		}
+		return h.dataResponse(200, map[string]any{"message": msg}, headers), nil
+
+	case "PATCH":
+		var body struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(req.Body), &body); err != nil {
+			return h.errorResponse(400, "Invalid request body", headers), nil
+		}
+
+		err := h.inboxService.SetStatus(ctx, id, body.Status)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidStatus) {
+				return h.errorResponse(400, err.Error(), headers), nil
+			}
+			if errors.Is(err, storage.ErrNotFound) {
+				return h.errorResponse(404, "Message not found", headers), nil
+			}
+			slog.ErrorContext(ctx, "Error updating message", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		return h.dataResponse(200, map[string]any{"id": id, "status": body.Status}, headers), nil
+
+	case "DELETE":
+		err := h.inboxService.DeleteMessage(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return h.errorResponse(404, "Message not found", headers), nil
+			}
+			slog.ErrorContext(ctx, "Error deleting message", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		return h.dataResponse(200, map[string]any{"id": id}, headers), nil
+
+	default:
+		return h.errorResponse(405, "Method not allowed", headers), nil
+	}
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates. A plain date used as
+// an upper bound covers the whole day.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
+
+func (h *APIHandler) dataResponse(statusCode int, data map[string]any, headers map[string]string) events.APIGatewayProxyResponse {
+	response := model.APIResponse{Success: true, Data: data}
+	body, _ := json.Marshal(response)
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
+
+// handleAdminBlocklist lists (GET), adds (POST) and removes (DELETE ?type=&value=) blocklist entries
+func (h *APIHandler) handleAdminBlocklist(ctx context.Context, req events.APIGatewayProxyRequest, headers map[string]string) (events.APIGatewayProxyResponse, error) {
+	switch req.HTTPMethod {
+	case "GET":
+		entries, err := h.blocklistService.ListEntries(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error listing blocklist", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		return h.dataResponse(200, map[string]any{"entries": entries}, headers), nil
+
+	case "POST":
+		var body struct {
+			Type      string     `json:"type"`
+			Value     string     `json:"value"`
+			Reason    string     `json:"reason"`
+			ExpiresAt *time.Time `json:"expires_at"`
+			TTL       string     `json:"ttl"` // alternative to expires_at, e.g. "72h"
+		}
+		if err := json.Unmarshal([]byte(req.Body), &body); err != nil {
+			return h.errorResponse(400, "Invalid request body", headers), nil
+		}
+
+		entry := &model.BlocklistEntry{Type: body.Type, Value: body.Value, Reason: body.Reason, ExpiresAt: body.ExpiresAt}
+		if body.TTL != "" {
+			ttl, err := time.ParseDuration(body.TTL)
+			if err != nil || ttl <= 0 {
+				return h.errorResponse(400, "Invalid ttl", headers), nil
+			}
+			expiresAt := time.Now().Add(ttl)
+			entry.ExpiresAt = &expiresAt
+		}
+
+		if err := h.blocklistService.AddEntry(ctx, entry); err != nil {
+			if errors.Is(err, service.ErrInvalidBlocklistEntry) {
+				return h.errorResponse(400, err.Error(), headers), nil
+			}
+			slog.ErrorContext(ctx, "Error adding blocklist entry", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		return h.dataResponse(201, map[string]any{"entry": entry}, headers), nil
+
+	case "DELETE":
+		entryType := req.QueryStringParameters["type"]
+		value := req.QueryStringParameters["value"]
+		if err := h.blocklistService.RemoveEntry(ctx, entryType, value); err != nil {
+			if errors.Is(err, service.ErrInvalidBlocklistEntry) {
+				return h.errorResponse(400, err.Error(), headers), nil
+			}
+			if errors.Is(err, storage.ErrNotFound) {
+				return h.errorResponse(404, "Entry not found", headers), nil
+			}
+			slog.ErrorContext(ctx, "Error removing blocklist entry", "error", err)
+			return h.errorResponse(500, "Database error", headers), nil
+		}
+		return h.dataResponse(200, map[string]any{"type": entryType}, headers), nil
+
+	default:
+		return h.errorResponse(405, "Method not allowed", headers), nil
+	}
+}
@@ -3,14 +3,23 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"main/internal/logging"
+	"main/internal/metrics"
 	"main/internal/model"
 	"main/internal/service"
+	"main/internal/tracing"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type APIHandler struct {
@@ -19,6 +28,17 @@ type APIHandler struct {
 	likesService        *service.LikeService
 	contactService      *service.ContactService
 	notificationService *service.NotificationService
+	csrfService         *service.CSRFService
+	rateLimitService    *service.RateLimitService
+	botDetector         *service.BotDetector
+	inboxService        *service.InboxService
+	adminAuthService    *service.AdminAuthService
+	blocklistService    *service.BlocklistService
+	outbox              *service.OutboxService
+	healthService       *service.HealthService
+	featureFlags        *service.FeatureFlagService
+	version             *model.VersionInfo
+	siteOrigin          string // the only origin allowed to make credentialed requests
 }
 
 func NewAPIHandler(
@@ -27,6 +47,16 @@ func NewAPIHandler(
 	likesService *service.LikeService,
 	contactService *service.ContactService,
 	notificationService *service.NotificationService,
+	csrfService *service.CSRFService,
+	rateLimitService *service.RateLimitService,
+	botDetector *service.BotDetector,
+	inboxService *service.InboxService,
+	adminAuthService *service.AdminAuthService,
+	blocklistService *service.BlocklistService,
+	outbox *service.OutboxService,
+	healthService *service.HealthService,
+	featureFlags *service.FeatureFlagService,
+	siteURL string,
 ) *APIHandler {
 	return &APIHandler{
 		sessionService:      sessionService,
@@ -34,28 +64,145 @@ func NewAPIHandler(
 		likesService:        likesService,
 		contactService:      contactService,
 		notificationService: notificationService,
+		csrfService:         csrfService,
+		rateLimitService:    rateLimitService,
+		botDetector:         botDetector,
+		inboxService:        inboxService,
+		adminAuthService:    adminAuthService,
+		blocklistService:    blocklistService,
+		outbox:              outbox,
+		healthService:       healthService,
+		featureFlags:        featureFlags,
+		version:             service.BuildVersion(),
+		siteOrigin:          originOf(siteURL),
 	}
 }
 
 var sessionIDCookieName string = "session_id"
 
+// publicRoutes answer any origin, without credentials
+var publicRoutes = map[string]bool{
+	"/api/getVisitorCount": true,
+	"/api/getLikeCount":    true,
+	"/api/health":          true,
+	"/api/ready":           true,
+	"/api/version":         true,
+}
+
+// originOf returns the origin of a URL, e.g. https://www.pwnph0fun.com for https://www.pwnph0fun.com/resume
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// routeOrigin returns the CORS origin a route answers to
+func (h *APIHandler) routeOrigin(resource string) string {
+	if publicRoutes[resource] {
+		return "*"
+	}
+	return h.siteOrigin
+}
+
+const errCodeValidationFailed = "validation_failed"
+
+// HandleRequest routes an API Gateway request and logs its outcome. Every line logged while
+// handling it carries the request ID, route, method and hashed session ID.
 func (h *APIHandler) HandleRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	start := time.Now()
+
 	// Extract session ID from cookie
 	sessionID := h.extractSessionID(req.Headers["cookie"])
 
+	ctx, span := tracing.StartServer(ctx, req.HTTPMethod+" "+req.Resource, req.Headers,
+		attribute.String("http.request.method", req.HTTPMethod),
+		attribute.String("http.route", req.Resource),
+		attribute.String("aws.request_id", req.RequestContext.RequestID),
+	)
+
+	ctx = logging.With(ctx,
+		slog.String("request_id", req.RequestContext.RequestID),
+		slog.String("route", req.Resource),
+		slog.String("method", req.HTTPMethod),
+	)
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		ctx = logging.With(ctx, slog.String("trace_id", traceID))
+	}
+	if sessionID != "" {
+		ctx = logging.With(ctx, slog.String("session", logging.Hash(sessionID)))
+	}
+
+	resp, err := h.route(ctx, req, sessionID)
+	latency := time.Since(start)
+
+	// API Gateway answers 502 when the function returns an error
+	status := resp.StatusCode
+	if err != nil {
+		status = 502
+	}
+	metrics.Emit(metrics.Dimensions{"Route": req.Resource, "Method": req.HTTPMethod, "StatusCode": strconv.Itoa(status)},
+		metrics.Milliseconds("Latency", latency),
+		metrics.Count("Requests", 1),
+	)
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if err == nil && status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	tracing.End(span, err)
+
+	attrs := []any{"status", resp.StatusCode, "latency_ms", latency.Milliseconds()}
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "Request failed", append(attrs, "error", err)...)
+	case resp.StatusCode >= 500:
+		slog.ErrorContext(ctx, "Request completed", attrs...)
+	default:
+		slog.InfoContext(ctx, "Request completed", attrs...)
+	}
+	return resp, err
+}
+
+func (h *APIHandler) route(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
+	// Liveness and version probes don't touch storage, so they skip the blocklist and rate limits.
+	// /api/ready is limited since it checks the admin token and runs the deep checks.
+	switch req.Resource {
+	case "/api/health":
+		return h.handleHealth(ctx, req)
+	case "/api/version":
+		return h.handleVersion(ctx, req)
+	}
+
+	// Turned off features don't count against rate limits
+	if resp, disabled := h.checkFeature(ctx, req, sessionID); disabled {
+		return resp, nil
+	}
+	if resp, limited := h.checkRateLimit(ctx, req, sessionID); limited {
+		return resp, nil
+	}
+
 	switch req.Resource {
+	case "/api/ready":
+		return h.handleReady(ctx, req)
 	case "/api/session":
 		return h.handleGetSession(ctx, req, sessionID)
 	case "/api/getVisitorCount":
 		return h.handleGetVisitorCount(ctx, req)
 	case "/api/incrementVisitorCount":
-		return h.handleIncrementVisitorCount(ctx, req, sessionID)
+		return h.withCSRF(h.handleIncrementVisitorCount)(ctx, req, sessionID)
 	case "/api/getLikeCount":
 		return h.handleGetLikeCount(ctx, req)
 	case "/api/toggleLike":
-		return h.handleToggleLike(ctx, req, sessionID)
+		return h.withCSRF(h.handleToggleLike)(ctx, req, sessionID)
 	case "/api/contact":
-		return h.handleContact(ctx, req)
+		return h.withCSRF(h.handleContact)(ctx, req, sessionID)
+	case "/api/admin/messages":
+		return h.withAdmin("GET,OPTIONS", h.handleAdminMessages)(ctx, req, sessionID)
+	case "/api/admin/messages/{id}":
+		return h.withAdmin("GET,PATCH,DELETE,OPTIONS", h.handleAdminMessage)(ctx, req, sessionID)
+	case "/api/admin/blocklist":
+		return h.withAdmin("GET,POST,DELETE,OPTIONS", h.handleAdminBlocklist)(ctx, req, sessionID)
 	default:
 		return events.APIGatewayProxyResponse{
 			StatusCode: 404,
@@ -65,13 +212,7 @@ func (h *APIHandler) HandleRequest(ctx context.Context, req events.APIGatewayPro
 }
 
 func (h *APIHandler) handleGetSession(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "https://www.pwnph0fun.com"
-	headers["Access-Control-Allow-Methods"] = "GET,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+	headers := corsHeaders(h.siteOrigin, "GET,OPTIONS")
 
 	// Handle CORS preflight request
 	if req.HTTPMethod == "OPTIONS" {
@@ -88,13 +229,16 @@ func (h *APIHandler) handleGetSession(ctx context.Context, req events.APIGateway
 
 	session, isNewSession, err := h.sessionService.GetOrCreateSession(ctx, sessionID)
 	if err != nil {
-		log.Printf("Error getting session: %v", err)
+		slog.ErrorContext(ctx, "Error getting session", "error", err)
 		return h.errorResponse(500, "Session error", headers), nil
 	}
 
 	response := map[string]any{
 		"has_visited": session.HasVisited,
 		"has_liked":   session.HasLiked,
+		"csrf_token":  h.csrfService.IssueToken(ctx, session.SessionID),
+		"form_token":  h.contactService.IssueFormToken(ctx, session.SessionID),
+		"features":    h.featureFlags.Features(ctx, session.SessionID, service.FlagLikes, service.FlagContact),
 	}
 
 	body, _ := json.Marshal(response)
@@ -112,13 +256,7 @@ func (h *APIHandler) handleGetSession(ctx context.Context, req events.APIGateway
 }
 
 func (h *APIHandler) handleGetVisitorCount(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "*"
-	headers["Access-Control-Allow-Methods"] = "GET,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+	headers := corsHeaders(h.routeOrigin(req.Resource), "GET,OPTIONS")
 
 	// Handle CORS preflight request
 	if req.HTTPMethod == "OPTIONS" {
@@ -135,7 +273,7 @@ func (h *APIHandler) handleGetVisitorCount(ctx context.Context, req events.APIGa
 
 	count, err := h.visitorService.GetVisitorCount(ctx)
 	if err != nil {
-		log.Printf("Error getting count: %v", err)
+		slog.ErrorContext(ctx, "Error getting count", "error", err)
 		return h.errorResponse(500, "Database error", headers), nil
 	}
 
@@ -150,13 +288,7 @@ func (h *APIHandler) handleGetVisitorCount(ctx context.Context, req events.APIGa
 }
 
 func (h *APIHandler) handleIncrementVisitorCount(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "https://www.pwnph0fun.com"
-	headers["Access-Control-Allow-Methods"] = "POST,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+	headers := corsHeaders(h.siteOrigin, "POST,OPTIONS")
 
 	// Handle CORS preflight request
 	if req.HTTPMethod == "OPTIONS" {
@@ -174,7 +306,7 @@ func (h *APIHandler) handleIncrementVisitorCount(ctx context.Context, req events
 	// Validate session exists before proceeding
 	session, err := h.sessionService.ValidateSession(ctx, sessionID)
 	if err != nil {
-		log.Printf("Error validating session: %v", err)
+		slog.ErrorContext(ctx, "Error validating session", "error", err)
 		return h.errorResponse(500, "Session error", headers), nil
 	}
 
@@ -182,9 +314,23 @@ func (h *APIHandler) handleIncrementVisitorCount(ctx context.Context, req events
 		return h.errorResponse(401, "Invalid session", headers), nil
 	}
 
-	count, status, err := h.visitorService.IncrementVisitorCount(ctx, session)
+	client := service.ClientInfo{
+		UserAgent:      getHeader(req.Headers, "User-Agent"),
+		SourceIP:       req.RequestContext.Identity.SourceIP,
+		AcceptLanguage: getHeader(req.Headers, "Accept-Language"),
+	}
+
+	var count int
+	var status string
+	// Sessions outside the bot_visits rollout are counted as visitors, as before bots were told apart
+	if isBot, reason := h.botDetector.Classify(client, session); isBot && h.featureFlags.EnabledFor(ctx, service.FlagBotVisits, session.SessionID) {
+		slog.InfoContext(ctx, "Not counting bot visit", "reason", reason, "user_agent", client.UserAgent)
+		count, status, err = h.visitorService.RecordBotVisit(ctx)
+	} else {
+		count, status, err = h.visitorService.IncrementVisitorCount(ctx, session)
+	}
 	if err != nil {
-		log.Printf("Error incrementing count: %v", err)
+		slog.ErrorContext(ctx, "Error incrementing count", "error", err)
 		return h.errorResponse(500, "Database error", headers), nil
 	}
 
@@ -193,7 +339,7 @@ func (h *APIHandler) handleIncrementVisitorCount(ctx context.Context, req events
 		session.HasVisited = true
 		err = h.sessionService.UpdateSession(ctx, session)
 		if err != nil {
-			log.Printf("Error updating session: %v", err)
+			slog.ErrorContext(ctx, "Error updating session", "error", err)
 			// Don't fail the request if session update fails
 		}
 	}
@@ -214,13 +360,7 @@ func (h *APIHandler) handleIncrementVisitorCount(ctx context.Context, req events
 }
 
 func (h *APIHandler) handleGetLikeCount(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "*"
-	headers["Access-Control-Allow-Methods"] = "GET,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+	headers := corsHeaders(h.routeOrigin(req.Resource), "GET,OPTIONS")
 
 	if req.HTTPMethod == "OPTIONS" {
 		return events.APIGatewayProxyResponse{
@@ -236,7 +376,7 @@ func (h *APIHandler) handleGetLikeCount(ctx context.Context, req events.APIGatew
 
 	count, err := h.likesService.GetLikeCount(ctx)
 	if err != nil {
-		log.Printf("Error getting likes: %v", err)
+		slog.ErrorContext(ctx, "Error getting likes", "error", err)
 		return h.errorResponse(500, "Database error", headers), nil
 	}
 
@@ -251,13 +391,7 @@ func (h *APIHandler) handleGetLikeCount(ctx context.Context, req events.APIGatew
 }
 
 func (h *APIHandler) handleToggleLike(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "https://www.pwnph0fun.com"
-	headers["Access-Control-Allow-Methods"] = "POST,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+	headers := corsHeaders(h.siteOrigin, "POST,OPTIONS")
 
 	// Handle CORS preflight request
 	if req.HTTPMethod == "OPTIONS" {
@@ -275,7 +409,7 @@ func (h *APIHandler) handleToggleLike(ctx context.Context, req events.APIGateway
 	// Validate session exists before proceeding
 	session, err := h.sessionService.ValidateSession(ctx, sessionID)
 	if err != nil {
-		log.Printf("Error validating session: %v", err)
+		slog.ErrorContext(ctx, "Error validating session", "error", err)
 		return h.errorResponse(500, "Session error", headers), nil
 	}
 
@@ -285,28 +419,29 @@ func (h *APIHandler) handleToggleLike(ctx context.Context, req events.APIGateway
 
 	count, action, err := h.likesService.ToggleLike(ctx, session)
 	if err != nil {
-		log.Printf("Error toggling like: %v", err)
+		slog.ErrorContext(ctx, "Error toggling like", "error", err)
 		return h.errorResponse(500, "Database error", headers), nil
 	}
 
 	// Update session with new like status
 	err = h.sessionService.UpdateSession(ctx, session)
 	if err != nil {
-		log.Printf("Error updating session: %v", err)
+		slog.ErrorContext(ctx, "Error updating session", "error", err)
 		// Don't fail the request if session update fails
 	}
 
-	// Send notification if this is a new like
-	if action == "liked" {
+	// Send notification if this is a new like, unless likes are only reported in the digest
+	// or this session was already notified about recently
+	if action == "liked" && !h.notificationService.DigestLikes(ctx) && !h.notificationService.IsDuplicate(ctx, "like", sessionID) {
 		payload := &model.NotificationPayload{
 			Type:      "like",
 			Data:      map[string]any{},
 			Source:    "resume-website",
 			Timestamp: time.Now(),
 		}
-		err = h.notificationService.SendEmailNotification(context.Background(), payload)
+		err = h.outbox.Notify(ctx, payload)
 		if err != nil {
-			log.Printf("notificationService: %v", err)
+			slog.ErrorContext(ctx, "Error sending notification", "error", err)
 		}
 	}
 
@@ -325,14 +460,8 @@ func (h *APIHandler) handleToggleLike(ctx context.Context, req events.APIGateway
 	}, nil
 }
 
-func (h *APIHandler) handleContact(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	var headers map[string]string = map[string]string{}
-
-	headers["Content-Type"] = "application/json"
-	headers["Access-Control-Allow-Origin"] = "https://www.pwnph0fun.com"
-	headers["Access-Control-Allow-Methods"] = "POST,OPTIONS"
-	headers["Access-Control-Allow-Headers"] = "Content-Type,Cookie"
-	headers["Access-Control-Allow-Credentials"] = "true"
+func (h *APIHandler) handleContact(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
+	headers := corsHeaders(h.siteOrigin, "POST,OPTIONS")
 
 	// Handle CORS preflight request
 	if req.HTTPMethod == "OPTIONS" {
@@ -353,23 +482,57 @@ func (h *APIHandler) handleContact(ctx context.Context, req events.APIGatewayPro
 		return h.errorResponse(400, "Invalid request body", headers), nil
 	}
 
-	if err := h.contactService.ProcessContactRequest(ctx, &contactReq); err != nil {
-		log.Printf("Error processing contact request: %v", err)
-		return h.errorResponse(400, fmt.Sprintf("Invalid request %v", err), headers), nil
+	if fieldErrs := service.ValidateContactRequest(&contactReq); len(fieldErrs) > 0 {
+		return h.validationErrorResponse(fieldErrs, headers), nil
+	}
+
+	msg, err := h.contactService.ProcessContactRequest(ctx, &contactReq, sessionID, req.RequestContext.Identity.SourceIP)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error processing contact request", "error", err)
+		if errors.Is(err, service.ErrBlocked) {
+			return h.codedErrorResponse(403, errCodeBlocked, "Forbidden", headers), nil
+		}
+		// The logged error says why the CAPTCHA was rejected, the response doesn't so bots
+		// can't learn the score threshold or the expected action and hostname
+		if errors.Is(err, service.ErrCaptchaFailed) {
+			return h.errorResponse(400, "CAPTCHA verification failed", headers), nil
+		}
+		if errors.Is(err, service.ErrCaptchaUnavailable) {
+			return h.errorResponse(503, "CAPTCHA verification is unavailable, please try again later", headers), nil
+		}
+		return h.errorResponse(500, "Could not save message", headers), nil
+	}
+
+	// Quarantined messages stay in the inbox for review. The sender gets the same
+	// response so the spam filter can't be probed.
+	if msg.Status == model.ContactStatusQuarantined {
+		slog.InfoContext(ctx, "Quarantined contact message", "message_id", msg.ID, "spam_score", msg.SpamScore, "spam_signals", msg.SpamSignals)
+		response := model.APIResponse{Success: true, Message: "Message sent successfully"}
+		responseBody, _ := json.Marshal(response)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    headers,
+			Body:       string(responseBody),
+		}, nil
 	}
 
 	// Send notification
 	payload := &model.NotificationPayload{
 		Type: "contact",
 		Data: map[string]any{
-			"name":    contactReq.Name,
-			"email":   contactReq.Email,
-			"message": contactReq.Message,
+			"id":      msg.ID,
+			"name":    msg.Name,
+			"email":   msg.Email,
+			"message": msg.Message,
 		},
-		Source: "resume-website",
+		Source:    "resume-website",
+		Timestamp: msg.CreatedAt,
 	}
-	go h.notificationService.SendEmailNotification(context.Background(), payload)
-	go h.notificationService.SendSMSNotification(context.Background(), payload)
+	if err := h.outbox.Notify(ctx, payload); err != nil {
+		slog.ErrorContext(ctx, "Error sending notification", "error", err)
+	}
+
+	h.sendAutoReply(ctx, msg)
 
 	response := model.APIResponse{Success: true, Message: "Message sent successfully"}
 	responseBody, _ := json.Marshal(response)
@@ -381,6 +544,28 @@ func (h *APIHandler) handleContact(ctx context.Context, req events.APIGatewayPro
 	}, nil
 }
 
+// sendAutoReply acknowledges a contact message to its sender, at most as often as the
+// auto_reply rate limit allows per recipient address
+func (h *APIHandler) sendAutoReply(ctx context.Context, msg *model.ContactMessage) {
+	if !h.notificationService.AutoReplyEnabled() {
+		return
+	}
+
+	allowed, _, err := h.rateLimitService.AllowKey(ctx, service.AutoReplyLimiter, strings.ToLower(msg.Email))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error checking auto-reply rate limit", "error", err)
+		return
+	}
+	if !allowed {
+		slog.InfoContext(ctx, "Skipping auto-reply, recipient over rate limit", "message_id", msg.ID)
+		return
+	}
+
+	if err := h.notificationService.SendAutoReply(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "Error sending notification", "error", err)
+	}
+}
+
 func (h *APIHandler) extractSessionID(cookieHeader string) string {
 	if cookieHeader == "" {
 		return ""
@@ -408,8 +593,53 @@ func parseCookies(cookieHeader string) map[string]string {
 	return cookies
 }
 
+// corsHeaders allows requests from allowOrigin. Credentials are only allowed for a specific
+// origin, browsers reject them with the "*" wildcard.
+func corsHeaders(allowOrigin, allowMethods string) map[string]string {
+	headers := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  allowOrigin,
+		"Access-Control-Allow-Methods": allowMethods,
+		"Access-Control-Allow-Headers": "Content-Type,Cookie," + csrfHeaderName,
+	}
+	if allowOrigin != "*" {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	return headers
+}
+
+// getHeader looks up a request header case-insensitively
+func getHeader(headers map[string]string, name string) string {
+	if value, ok := headers[name]; ok {
+		return value
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
 func (h *APIHandler) errorResponse(statusCode int, message string, headers map[string]string) events.APIGatewayProxyResponse {
-	response := model.APIResponse{Error: message, Success: false}
+	return h.codedErrorResponse(statusCode, "", message, headers)
+}
+
+// validationErrorResponse returns a 422 listing every rejected field
+func (h *APIHandler) validationErrorResponse(fieldErrs []model.FieldError, headers map[string]string) events.APIGatewayProxyResponse {
+	response := model.APIResponse{Error: "Validation failed", Code: errCodeValidationFailed, Errors: fieldErrs, Success: false}
+	body, _ := json.Marshal(response)
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 422,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
+
+// codedErrorResponse is errorResponse with a machine-readable error code for the client
+func (h *APIHandler) codedErrorResponse(statusCode int, code, message string, headers map[string]string) events.APIGatewayProxyResponse {
+	response := model.APIResponse{Error: message, Code: code, Success: false}
 	body, _ := json.Marshal(response)
 
 	return events.APIGatewayProxyResponse{
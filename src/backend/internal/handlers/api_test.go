@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIHandler_RouteOrigin(t *testing.T) {
+	tests := []struct {
+		name           string
+		siteURL        string
+		resource       string
+		expectedOrigin string
+	}{
+		{
+			name:           "credentialed route",
+			siteURL:        "https://www.pwnph0fun.com/resume",
+			resource:       "/api/contact",
+			expectedOrigin: "https://www.pwnph0fun.com",
+		},
+		{
+			name:           "public route",
+			siteURL:        "https://www.pwnph0fun.com",
+			resource:       "/api/getVisitorCount",
+			expectedOrigin: "*",
+		},
+		{
+			name:           "site URL with a port",
+			siteURL:        "http://localhost:3000",
+			resource:       "/api/session",
+			expectedOrigin: "http://localhost:3000",
+		},
+		{
+			name:           "invalid site URL",
+			siteURL:        "www.pwnph0fun.com",
+			resource:       "/api/session",
+			expectedOrigin: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, tt.siteURL)
+
+			assert.Equal(t, tt.expectedOrigin, h.routeOrigin(tt.resource))
+		})
+	}
+}
+
+func TestIsAllowedOrigin(t *testing.T) {
+	tests := []struct {
+		name       string
+		siteOrigin string
+		headers    map[string]string
+		expected   bool
+	}{
+		{"matching origin", "https://www.pwnph0fun.com", map[string]string{"Origin": "https://www.pwnph0fun.com"}, true},
+		{"other origin", "https://www.pwnph0fun.com", map[string]string{"Origin": "https://evil.example"}, false},
+		{"matching referer", "https://www.pwnph0fun.com", map[string]string{"Referer": "https://www.pwnph0fun.com/contact"}, true},
+		{"no origin or referer", "https://www.pwnph0fun.com", map[string]string{}, false},
+		{"no site origin configured", "", map[string]string{"Origin": ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isAllowedOrigin(tt.headers, tt.siteOrigin))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const csrfHeaderName = "X-CSRF-Token"
+
+const (
+	errCodeOriginNotAllowed = "origin_not_allowed"
+	errCodeCSRFTokenInvalid = "csrf_token_invalid"
+)
+
+type sessionHandlerFunc func(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error)
+
+// withCSRF protects a state-changing route: the request must come from the site origin
+// and carry the CSRF token issued by /api/session for its session cookie
+func (h *APIHandler) withCSRF(next sessionHandlerFunc) sessionHandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, error) {
+		// CORS preflight requests can't change state
+		if req.HTTPMethod == "OPTIONS" {
+			return next(ctx, req, sessionID)
+		}
+
+		headers := corsHeaders(h.siteOrigin, "POST,OPTIONS")
+
+		if !isAllowedOrigin(req.Headers, h.siteOrigin) {
+			return h.codedErrorResponse(403, errCodeOriginNotAllowed, "Origin not allowed", headers), nil
+		}
+
+		if !h.csrfService.ValidateToken(ctx, sessionID, getHeader(req.Headers, csrfHeaderName)) {
+			return h.codedErrorResponse(403, errCodeCSRFTokenInvalid, "Invalid CSRF token", headers), nil
+		}
+
+		return next(ctx, req, sessionID)
+	}
+}
+
+// isAllowedOrigin checks the Origin header, falling back to Referer when a browser omits it.
+// Requests carrying neither are rejected.
+func isAllowedOrigin(headers map[string]string, siteOrigin string) bool {
+	if siteOrigin == "" {
+		return false
+	}
+	if origin := getHeader(headers, "Origin"); origin != "" {
+		return origin == siteOrigin
+	}
+
+	return originOf(getHeader(headers, "Referer")) == siteOrigin
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"main/internal/logging"
+	"main/internal/service"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// jobEvent is the input of a scheduled invocation. EventBridge rules pass the job name either
+// as constant input ({"job": "digest"}) or in the event detail.
+type jobEvent struct {
+	Job    string `json:"job"`
+	Detail struct {
+		Job string `json:"job"`
+	} `json:"detail"`
+}
+
+// eventShape holds the fields used to tell the supported Lambda events apart
+type eventShape struct {
+	HTTPMethod string `json:"httpMethod"`
+	Records    []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+}
+
+// EventRouter is the Lambda entry point. It serves API Gateway requests, delivers new outbox
+// entries from the outbox table's DynamoDB stream, and runs background jobs on EventBridge schedules.
+type EventRouter struct {
+	api    *APIHandler
+	outbox *service.OutboxService
+	jobs   *service.JobRegistry
+}
+
+func NewEventRouter(api *APIHandler, outbox *service.OutboxService, jobs *service.JobRegistry) *EventRouter {
+	return &EventRouter{api: api, outbox: outbox, jobs: jobs}
+}
+
+func (r *EventRouter) HandleEvent(ctx context.Context, raw json.RawMessage) (any, error) {
+	var shape eventShape
+	if err := json.Unmarshal(raw, &shape); err != nil {
+		return nil, err
+	}
+
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		ctx = logging.With(ctx, slog.String("aws_request_id", lc.AwsRequestID))
+	}
+
+	switch {
+	case shape.HTTPMethod != "":
+		var req events.APIGatewayProxyRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		return r.api.HandleRequest(ctx, req)
+
+	case len(shape.Records) > 0 && shape.Records[0].EventSource == "aws:dynamodb":
+		var streamEvent events.DynamoDBEvent
+		if err := json.Unmarshal(raw, &streamEvent); err != nil {
+			return nil, err
+		}
+		ctx = logging.With(ctx, slog.String("event", "outbox_stream"))
+		return nil, r.outbox.HandleStreamEvent(ctx, streamEvent)
+
+	default:
+		var event jobEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, err
+		}
+		job := event.Job
+		if job == "" {
+			job = event.Detail.Job
+		}
+		if job == "" {
+			return nil, errors.New("unsupported event: no job name in scheduled event")
+		}
+		ctx = logging.With(ctx, slog.String("job", job))
+		err := r.jobs.Run(ctx, job)
+		if err != nil {
+			slog.ErrorContext(ctx, "Job failed", "error", err)
+		}
+		return nil, err
+	}
+}
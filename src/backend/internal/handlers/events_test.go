@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/service"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOutboxStorage records the entries the stream handler loads
+type fakeOutboxStorage struct {
+	loaded []string
+}
+
+func (f *fakeOutboxStorage) CreateOutboxEntry(ctx context.Context, entry *model.OutboxEntry) error {
+	return nil
+}
+
+func (f *fakeOutboxStorage) GetOutboxEntry(ctx context.Context, id string) (*model.OutboxEntry, error) {
+	f.loaded = append(f.loaded, id)
+	return nil, nil
+}
+
+func (f *fakeOutboxStorage) ListDueOutboxEntries(ctx context.Context, now time.Time, limit int32) ([]model.OutboxEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeOutboxStorage) UpdateOutboxEntry(ctx context.Context, entry *model.OutboxEntry, prevAttempts int) error {
+	return nil
+}
+
+func TestEventRouter_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		event          string
+		expectedStatus int
+		expectedJobs   []string
+		expectedLoaded []string
+		errorMessage   string
+	}{
+		{
+			name:           "API Gateway request",
+			event:          `{"httpMethod": "GET", "resource": "/api/version", "path": "/api/version", "headers": {}}`,
+			expectedStatus: 200,
+		},
+		{
+			name: "outbox stream",
+			event: `{"Records": [
+				{"eventSource": "aws:dynamodb", "eventName": "INSERT", "dynamodb": {"Keys": {"ID": {"S": "entry-1"}}}},
+				{"eventSource": "aws:dynamodb", "eventName": "MODIFY", "dynamodb": {"Keys": {"ID": {"S": "entry-2"}}}}
+			]}`,
+			expectedLoaded: []string{"entry-1"},
+		},
+		{
+			name:         "scheduled job as constant input",
+			event:        `{"job": "digest"}`,
+			expectedJobs: []string{"digest"},
+		},
+		{
+			name:         "scheduled job in event detail",
+			event:        `{"source": "aws.events", "detail-type": "Scheduled Event", "detail": {"job": "counter_rollup"}}`,
+			expectedJobs: []string{"counter_rollup"},
+		},
+		{
+			name:         "failing job",
+			event:        `{"job": "outbox_retry"}`,
+			expectedJobs: []string{"outbox_retry"},
+			errorMessage: "job outbox_retry failed",
+		},
+		{
+			name:         "unknown job",
+			event:        `{"job": "reindex"}`,
+			errorMessage: `unknown job: "reindex"`,
+		},
+		{
+			name:         "scheduled event without a job",
+			event:        `{"source": "aws.events", "detail": {}}`,
+			errorMessage: "no job name in scheduled event",
+		},
+		{
+			name:         "malformed event",
+			event:        `[]`,
+			errorMessage: "cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			jobs := service.NewJobRegistry(nil)
+			for _, name := range []string{"digest", "counter_rollup", "outbox_retry"} {
+				jobs.Register(name, func(ctx context.Context, cfg config.JobConfig) error {
+					ran = append(ran, name)
+					if name == "outbox_retry" {
+						return errors.New("throttled")
+					}
+					return nil
+				})
+			}
+
+			outboxStorage := &fakeOutboxStorage{}
+			outbox := service.NewOutboxService(outboxStorage, nil, nil, &config.Config{})
+			api := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, outbox, nil, nil, "https://www.pwnph0fun.com")
+			router := NewEventRouter(api, outbox, jobs)
+
+			result, err := router.HandleEvent(context.Background(), json.RawMessage(tt.event))
+
+			if tt.errorMessage != "" {
+				assert.ErrorContains(t, err, tt.errorMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.expectedStatus != 0 {
+				resp, ok := result.(events.APIGatewayProxyResponse)
+				assert.True(t, ok, "API Gateway requests get an API Gateway response")
+				assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			} else {
+				assert.Nil(t, result)
+			}
+			assert.Equal(t, tt.expectedJobs, ran)
+			assert.Equal(t, tt.expectedLoaded, outboxStorage.loaded)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"main/internal/service"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const errCodeFeatureDisabled = "feature_disabled"
+
+// featureRoute is a route that is turned off with its feature
+type featureRoute struct {
+	flag   string
+	status int
+}
+
+// featureRoutes answer 404 when their feature is off, as if they didn't exist, or 503 when
+// the site should tell visitors to come back later
+var featureRoutes = map[string]featureRoute{
+	"/api/getLikeCount": {service.FlagLikes, 404},
+	"/api/toggleLike":   {service.FlagLikes, 404},
+	"/api/contact":      {service.FlagContact, 503},
+}
+
+// checkFeature returns the response for a route whose feature is off for the caller's session.
+// CORS preflights go through so the browser lets the site read the response.
+func (h *APIHandler) checkFeature(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, bool) {
+	route, ok := featureRoutes[req.Resource]
+	if !ok || req.HTTPMethod == "OPTIONS" || h.featureFlags.EnabledFor(ctx, route.flag, sessionID) {
+		return events.APIGatewayProxyResponse{}, false
+	}
+
+	headers := corsHeaders(h.routeOrigin(req.Resource), req.HTTPMethod+",OPTIONS")
+	if route.status == 404 {
+		return h.errorResponse(404, "Not found", headers), true
+	}
+	return h.codedErrorResponse(route.status, errCodeFeatureDisabled, "Temporarily unavailable", headers), true
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"main/internal/model"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func healthHeaders() map[string]string {
+	headers := corsHeaders("*", "GET,OPTIONS")
+	headers["Access-Control-Allow-Headers"] += ",Authorization"
+	headers["Cache-Control"] = "no-store"
+	return headers
+}
+
+// handleHealth answers liveness probes without touching any dependency: GET /api/health
+func (h *APIHandler) handleHealth(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return h.healthResponse(req, func(headers map[string]string) events.APIGatewayProxyResponse {
+		return jsonResponse(200, map[string]string{"status": "ok"}, headers)
+	}), nil
+}
+
+// handleReady reports whether the backend can serve requests: GET /api/ready
+// Callers presenting the admin bearer token get the deep checks with the outcome of each.
+func (h *APIHandler) handleReady(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return h.healthResponse(req, func(headers map[string]string) events.APIGatewayProxyResponse {
+		deep := false
+		if authHeader := getHeader(req.Headers, "Authorization"); authHeader != "" {
+			if !h.adminAuthService.Authorize(ctx, authHeader) {
+				return h.codedErrorResponse(401, errCodeUnauthorized, "Unauthorized", headers)
+			}
+			deep = true
+		}
+
+		report := h.healthService.Ready(ctx, deep)
+		status := 200
+		if report.Status != model.ReadinessReady {
+			status = 503
+		}
+		return jsonResponse(status, report, headers)
+	}), nil
+}
+
+// handleVersion describes the running build: GET /api/version
+func (h *APIHandler) handleVersion(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return h.healthResponse(req, func(headers map[string]string) events.APIGatewayProxyResponse {
+		return jsonResponse(200, h.version, headers)
+	}), nil
+}
+
+// healthResponse answers CORS preflights and rejects methods other than GET before calling next
+func (h *APIHandler) healthResponse(req events.APIGatewayProxyRequest, next func(headers map[string]string) events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	headers := healthHeaders()
+
+	// Handle CORS preflight request
+	if req.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 204,
+			Headers:    headers,
+			Body:       "",
+		}
+	}
+
+	if req.HTTPMethod != "GET" {
+		return h.errorResponse(405, "Method not allowed", headers)
+	}
+	return next(headers)
+}
+
+func jsonResponse(statusCode int, v any, headers map[string]string) events.APIGatewayProxyResponse {
+	body, _ := json.Marshal(v)
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	errCodeRateLimited = "rate_limited"
+	errCodeBlocked     = "blocked"
+)
+
+// checkRateLimit turns away blocklisted IPs and applies the route's rate limit to the caller's
+// source IP and session. It returns a 403 or 429 response when the request must be rejected.
+// Storage failures fail open.
+func (h *APIHandler) checkRateLimit(ctx context.Context, req events.APIGatewayProxyRequest, sessionID string) (events.APIGatewayProxyResponse, bool) {
+	if req.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{}, false
+	}
+
+	blocked, err := h.rateLimitService.Blocked(ctx, req.RequestContext.Identity.SourceIP)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error checking blocklist", "error", err)
+	}
+	if blocked {
+		headers := corsHeaders(h.routeOrigin(req.Resource), req.HTTPMethod+",OPTIONS")
+		return h.codedErrorResponse(403, errCodeBlocked, "Forbidden", headers), true
+	}
+
+	allowed, retryAfter, err := h.rateLimitService.Allow(ctx, req.Resource, req.RequestContext.Identity.SourceIP, sessionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error checking rate limit", "error", err)
+		return events.APIGatewayProxyResponse{}, false
+	}
+	if allowed {
+		return events.APIGatewayProxyResponse{}, false
+	}
+
+	headers := corsHeaders(h.routeOrigin(req.Resource), req.HTTPMethod+",OPTIONS")
+	headers["Retry-After"] = strconv.Itoa(int(retryAfter.Seconds()))
+	return h.codedErrorResponse(429, errCodeRateLimited, "Too many requests", headers), true
+}
@@ -0,0 +1,84 @@
+// Package logging sets up JSON logging with log/slog. Attributes attached to a context with
+// With are added to every line logged with that context.
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// level is shared by every logger created by this package, so it can change after setup
+var level = new(slog.LevelVar)
+
+// contextHandler adds the attributes stored in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(ctxKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// New creates a JSON logger writing to w
+func New(w io.Writer) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// Setup makes a JSON logger writing to stderr the default logger, also used by the log package
+func Setup() {
+	slog.SetDefault(New(os.Stderr))
+}
+
+// SetLevel sets the minimum level of every logger created by this package
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel returns the named level (debug, info, warn or error). Without a name, it is
+// info in production and debug in other environments.
+func ParseLevel(name, environment string) slog.Level {
+	var l slog.Level
+	if name != "" && l.UnmarshalText([]byte(name)) == nil {
+		return l
+	}
+	switch strings.ToLower(environment) {
+	case "prod", "production":
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// With returns a context whose log lines carry attrs, in addition to those already attached
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, ctxKey{}, combined)
+}
+
+// Hash shortens an identifier such as a session ID to a stable value that can be logged
+// without revealing it
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf)
+	SetLevel(slog.LevelInfo)
+
+	ctx := With(context.Background(), slog.String("request_id", "req-1"))
+	ctx = With(ctx, slog.String("route", "/api/contact"))
+	logger.InfoContext(ctx, "request completed", "status", 200)
+	logger.DebugContext(ctx, "not logged at info level")
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "request completed", line["msg"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/api/contact", line["route"])
+	assert.Equal(t, float64(200), line["status"])
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, ParseLevel("warn", "prod"))
+	assert.Equal(t, slog.LevelInfo, ParseLevel("", "prod"))
+	assert.Equal(t, slog.LevelDebug, ParseLevel("", "dev"))
+	assert.Equal(t, slog.LevelDebug, ParseLevel("nonsense", "dev"))
+}
+
+func TestHash(t *testing.T) {
+	assert.Len(t, Hash("session"), 16)
+	assert.Equal(t, Hash("session"), Hash("session"))
+	assert.NotEqual(t, Hash("session"), Hash("other"))
+}
@@ -0,0 +1,144 @@
+// Package metrics emits CloudWatch metrics in the Embedded Metric Format (EMF). Each call
+// writes one JSON line that CloudWatch Logs turns into metrics, so nothing is sent over the
+// network and the output can be inspected offline.
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Namespace is the CloudWatch namespace metrics are published under
+const Namespace = "PortfolioBackend"
+
+// Unit is a CloudWatch metric unit
+type Unit string
+
+const (
+	UnitCount        Unit = "Count"
+	UnitMilliseconds Unit = "Milliseconds"
+	UnitNone         Unit = "None"
+)
+
+// Metric is a single named value
+type Metric struct {
+	Name  string
+	Value float64
+	Unit  Unit
+}
+
+// Count returns a count metric
+func Count(name string, n int) Metric {
+	return Metric{Name: name, Value: float64(n), Unit: UnitCount}
+}
+
+// Milliseconds returns a latency metric
+func Milliseconds(name string, d time.Duration) Metric {
+	return Metric{Name: name, Value: float64(d.Microseconds()) / 1000, Unit: UnitMilliseconds}
+}
+
+// Value returns a unitless metric, such as a score
+func Value(name string, v float64) Metric {
+	return Metric{Name: name, Value: v, Unit: UnitNone}
+}
+
+// Dimensions are the name/value pairs metrics are grouped by
+type Dimensions map[string]string
+
+// Recorder writes EMF records to a writer. Every record carries the Environment dimension.
+type Recorder struct {
+	mu          sync.Mutex
+	w           io.Writer
+	namespace   string
+	environment string
+	now         func() time.Time
+}
+
+// New creates a recorder writing to w
+func New(w io.Writer, namespace, environment string) *Recorder {
+	return &Recorder{w: w, namespace: namespace, environment: environment, now: time.Now}
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit Unit   `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// Emit writes one record with the given metrics, grouped by dims. A nil recorder does nothing.
+func (r *Recorder) Emit(dims Dimensions, metrics ...Metric) {
+	if r == nil || len(metrics) == 0 {
+		return
+	}
+
+	record := map[string]any{}
+	names := []string{"Environment"}
+	record["Environment"] = r.environment
+	keys := make([]string, 0, len(dims))
+	for name := range dims {
+		if name != "Environment" {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		names = append(names, name)
+		record[name] = dims[name]
+	}
+
+	directive := emfDirective{Namespace: r.namespace, Dimensions: [][]string{names}}
+	for _, m := range metrics {
+		directive.Metrics = append(directive.Metrics, emfMetric{Name: m.Name, Unit: m.Unit})
+		record[m.Name] = m.Value
+	}
+	record["_aws"] = emfMetadata{
+		Timestamp:         r.now().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{directive},
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.w.Write(append(line, '\n'))
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRecorder *Recorder
+)
+
+// SetDefault sets the recorder used by the package-level Emit. Until it is called, metrics
+// are discarded.
+func SetDefault(r *Recorder) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRecorder = r
+}
+
+// Default returns the recorder used by the package-level Emit, which may be nil
+func Default() *Recorder {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRecorder
+}
+
+// Emit writes a record with the default recorder
+func Emit(dims Dimensions, metrics ...Metric) {
+	Default().Emit(dims, metrics...)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Emit(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, "Test", "prod")
+	r.now = func() time.Time { return time.UnixMilli(1735725600000) }
+
+	r.Emit(Dimensions{"Route": "/api/toggleLike", "Method": "POST"},
+		Milliseconds("Latency", 1500*time.Microsecond),
+		Count("Requests", 1),
+	)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "prod", record["Environment"])
+	assert.Equal(t, "/api/toggleLike", record["Route"])
+	assert.Equal(t, 1.5, record["Latency"])
+	assert.Equal(t, float64(1), record["Requests"])
+
+	meta := record["_aws"].(map[string]any)
+	assert.Equal(t, float64(1735725600000), meta["Timestamp"])
+	directive := meta["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Test", directive["Namespace"])
+	assert.Equal(t, []any{[]any{"Environment", "Method", "Route"}}, directive["Dimensions"])
+	assert.Equal(t, []any{
+		map[string]any{"Name": "Latency", "Unit": "Milliseconds"},
+		map[string]any{"Name": "Requests", "Unit": "Count"},
+	}, directive["Metrics"])
+}
+
+func TestRecorder_NilAndDefault(t *testing.T) {
+	var r *Recorder
+	r.Emit(nil, Count("Ignored", 1))
+
+	var buf bytes.Buffer
+	SetDefault(New(&buf, "Test", "dev"))
+	defer SetDefault(nil)
+
+	Emit(nil, Value("CaptchaScore", 0.9), Count("CaptchaVerifications", 1))
+	Emit(nil)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, 0.9, record["CaptchaScore"])
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
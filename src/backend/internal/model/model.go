@@ -12,30 +12,130 @@ type UserSession struct {
 	HasVisited bool      `dynamodbav:"HasVisited" json:"has_visited"`
 	HasLiked   bool      `dynamodbav:"HasLiked" json:"has_liked"`
 	ExpiresAt  time.Time `dynamodbav:"ExpiresAt" json:"expires_at"`
+	TTL        int64     `dynamodbav:"TTL,omitempty" json:"-"` // ExpiresAt in epoch seconds, for the table's TTL
 	CreatedAt  time.Time `dynamodbav:"CreatedAt" json:"created_at"`
 	UpdatedAt  time.Time `dynamodbav:"UpdatedAt" json:"updated_at"`
 }
 
 type ContactRequest struct {
-	Name      string `json:"name" validate:"required"`
-	Email     string `json:"email" validate:"required,email"`
-	Message   string `json:"message" validate:"required"`
-	Recaptcha string `json:"recaptcha" validate:"required"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Email     string `json:"email" validate:"required,email,max=254"`
+	Message   string `json:"message" validate:"required,max=5000"`
+	Recaptcha string `json:"recaptcha" validate:"required,max=4096"`
+	Website   string `json:"website" validate:"max=200"`    // honeypot, hidden from humans
+	FormToken string `json:"form_token" validate:"max=200"` // issued by /api/session to time the form fill
+}
+
+// Contact message statuses in the inbox
+const (
+	ContactStatusNew      = "new"
+	ContactStatusRead     = "read"
+	ContactStatusArchived = "archived"
+	ContactStatusSpam     = "spam"
+
+	// ContactStatusQuarantined marks messages held back by the spam filter without notifications
+	ContactStatusQuarantined = "quarantined"
+)
+
+// ContactMessage is a contact form submission stored in the inbox table
+type ContactMessage struct {
+	ID           string    `dynamodbav:"ID" json:"id"`
+	CreatedAt    time.Time `dynamodbav:"CreatedAt,unixtime" json:"created_at"`
+	Name         string    `dynamodbav:"Name" json:"name"`
+	Email        string    `dynamodbav:"Email" json:"email"`
+	Message      string    `dynamodbav:"Message" json:"message"`
+	CaptchaScore float64   `dynamodbav:"CaptchaScore" json:"captcha_score"`
+	SourceIPHash string    `dynamodbav:"SourceIPHash" json:"source_ip_hash"`
+	Status       string    `dynamodbav:"Status" json:"status"`
+	SpamScore    float64   `dynamodbav:"SpamScore" json:"spam_score"`
+	SpamSignals  []string  `dynamodbav:"SpamSignals,omitempty" json:"spam_signals,omitempty"`
+}
+
+// Blocklist entry types
+const (
+	BlockTypeEmail  = "email"
+	BlockTypeDomain = "domain"
+	BlockTypeIP     = "ip" // SHA-256 hash of the address, as in ContactMessage.SourceIPHash
+	BlockTypeCIDR   = "cidr"
+)
+
+// BlocklistEntry blocks a sender by email address, email domain, IP or IP range.
+// Entries without ExpiresAt never expire.
+type BlocklistEntry struct {
+	Type      string     `dynamodbav:"Type" json:"type"`
+	Value     string     `dynamodbav:"Value" json:"value"`
+	Reason    string     `dynamodbav:"Reason,omitempty" json:"reason,omitempty"`
+	CreatedAt time.Time  `dynamodbav:"CreatedAt,unixtime" json:"created_at"`
+	ExpiresAt *time.Time `dynamodbav:"ExpiresAt,unixtime,omitempty" json:"expires_at,omitempty"`
 }
 
 type APIResponse struct {
 	Count   int            `json:"count,omitempty"`
 	Message string         `json:"message,omitempty"`
 	Error   string         `json:"error,omitempty"`
+	Code    string         `json:"code,omitempty"`
 	Success bool           `json:"success"`
 	Data    map[string]any `json:"data,omitempty"`
+	Errors  []FieldError   `json:"errors,omitempty"`
+}
+
+// FieldError describes why a request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 type NotificationPayload struct {
-	Type      string         `json:"type"`
-	Data      map[string]any `json:"data"`
-	Source    string         `json:"source"`
-	Timestamp time.Time      `json:"timestamp"`
+	Type      string         `dynamodbav:"Type" json:"type"`
+	Data      map[string]any `dynamodbav:"Data" json:"data"`
+	Source    string         `dynamodbav:"Source" json:"source"`
+	Timestamp time.Time      `dynamodbav:"Timestamp" json:"timestamp"`
+}
+
+// CounterSnapshot is the daily rollup of the counters
+type CounterSnapshot struct {
+	ID        string    `dynamodbav:"ID" json:"-"`
+	Date      string    `dynamodbav:"Date" json:"date"` // YYYY-MM-DD
+	Likes     int       `dynamodbav:"Likes" json:"likes"`
+	Visitors  int       `dynamodbav:"Visitors" json:"visitors"`
+	BotVisits int       `dynamodbav:"BotVisits" json:"bot_visits"`
+	CreatedAt time.Time `dynamodbav:"CreatedAt,unixtime" json:"created_at"`
+}
+
+// DigestState records the counter totals at the time the last digest was sent
+type DigestState struct {
+	ID       string    `dynamodbav:"ID"`
+	Likes    int       `dynamodbav:"Likes"`
+	Visitors int       `dynamodbav:"Visitors"`
+	SentAt   time.Time `dynamodbav:"SentAt,unixtime"`
+}
+
+// FeatureFlagOverrides overrides the configured feature flags without a deploy. Flags map a
+// flag name to "on", "off" or a rollout percentage such as "25%".
+type FeatureFlagOverrides struct {
+	ID    string            `dynamodbav:"ID"`
+	Flags map[string]string `dynamodbav:"Flags"`
+}
+
+// Outbox entry statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead" // gave up after the maximum number of attempts
+)
+
+// OutboxEntry is a notification waiting to be delivered by the outbox worker
+type OutboxEntry struct {
+	ID            string              `dynamodbav:"ID" json:"id"`
+	Status        string              `dynamodbav:"Status" json:"status"`
+	Payload       NotificationPayload `dynamodbav:"Payload" json:"payload"`
+	Attempts      int                 `dynamodbav:"Attempts" json:"attempts"`
+	Delivered     []string            `dynamodbav:"Delivered,omitempty" json:"delivered,omitempty"` // channels that already succeeded
+	LastError     string              `dynamodbav:"LastError,omitempty" json:"last_error,omitempty"`
+	CreatedAt     time.Time           `dynamodbav:"CreatedAt,unixtime" json:"created_at"`
+	NextAttemptAt time.Time           `dynamodbav:"NextAttemptAt,unixtime" json:"next_attempt_at"`
+	ExpiresAt     *time.Time          `dynamodbav:"ExpiresAt,unixtime,omitempty" json:"expires_at,omitempty"`
 }
 
 type RecaptchaResponse struct {
@@ -46,3 +146,31 @@ type RecaptchaResponse struct {
 	Hostname    string   `json:"hostname"`
 	ErrorCodes  []string `json:"error-codes,omitempty"`
 }
+
+// Readiness statuses
+const (
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not_ready"
+)
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name      string `json:"name"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// ReadinessReport is the answer of /api/ready. Checks are only listed for deep checks.
+type ReadinessReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+// VersionInfo describes the running build
+type VersionInfo struct {
+	Commit     string `json:"commit"`
+	CommitTime string `json:"commit_time,omitempty"` // when the commit was made, not when it was built
+	Modified   bool   `json:"modified"`
+	GoVersion  string `json:"go_version"`
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"main/internal/config"
+	"strings"
+)
+
+type AdminAuthService struct {
+	token config.Secret
+}
+
+func NewAdminAuthService(token config.Secret) *AdminAuthService {
+	return &AdminAuthService{token: token}
+}
+
+// Authorize checks the bearer token of an Authorization header.
+// Admin access is disabled when no token is configured.
+func (as *AdminAuthService) Authorize(ctx context.Context, authHeader string) bool {
+	expectedToken := as.token(ctx)
+	if expectedToken == "" {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+
+	// Compare digests so the comparison doesn't leak the token length
+	expected := sha256.Sum256([]byte(expectedToken))
+	actual := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(expected[:], actual[:]) == 1
+}
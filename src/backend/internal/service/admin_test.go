@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"main/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuthService_Authorize(t *testing.T) {
+	service := NewAdminAuthService(config.StaticSecret("s3cret"))
+
+	assert.True(t, service.Authorize(context.Background(), "Bearer s3cret"))
+	assert.False(t, service.Authorize(context.Background(), "Bearer wrong"))
+	assert.False(t, service.Authorize(context.Background(), "s3cret"))
+	assert.False(t, service.Authorize(context.Background(), ""))
+	assert.False(t, NewAdminAuthService(config.StaticSecret("")).Authorize(context.Background(), "Bearer "))
+}
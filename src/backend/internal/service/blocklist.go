@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrBlocked is returned for requests from a blocklisted sender
+var ErrBlocked = errors.New("sender is blocked")
+
+// ErrInvalidBlocklistEntry is returned for entries with an unknown type or malformed value
+var ErrInvalidBlocklistEntry = errors.New("invalid blocklist entry")
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+var blocklistTypes = []string{model.BlockTypeEmail, model.BlockTypeDomain, model.BlockTypeIP, model.BlockTypeCIDR}
+
+// blocklistSnapshot is the in-memory copy of the blocklist
+type blocklistSnapshot struct {
+	values    map[string]map[string]model.BlocklistEntry // type -> value -> entry
+	cidrs     []netip.Prefix
+	expiresAt time.Time // earliest entry expiry, forces a refresh when reached
+}
+
+type BlocklistService struct {
+	storage  storage.BlocklistStorageInterface
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	snapshot   *blocklistSnapshot
+	loadErr    error     // last load failure, returned while there is no snapshot
+	reloadAt   time.Time // when the snapshot, or the last failure, stops being served
+	generation int       // changed by invalidate, so a load racing a change doesn't hold on to old entries
+}
+
+// NewBlocklistService creates a blocklist cached in memory for cacheTTL.
+// Nothing is blocked when storage is nil.
+func NewBlocklistService(storage storage.BlocklistStorageInterface, cacheTTL time.Duration) *BlocklistService {
+	return &BlocklistService{
+		storage:  storage,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// IsEmailBlocked checks an email address and its domain, including parent domains
+func (bs *BlocklistService) IsEmailBlocked(ctx context.Context, email string) (blocked bool, err error) {
+	ctx, span := tracing.Start(ctx, "BlocklistService.IsEmailBlocked")
+	defer func() { tracing.End(span, err) }()
+
+	snapshot, err := bs.load(ctx)
+	if err != nil || snapshot == nil {
+		return false, err
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, ok := snapshot.values[model.BlockTypeEmail][email]; ok {
+		return true, nil
+	}
+
+	_, domain, ok := strings.Cut(email, "@")
+	for ok && domain != "" {
+		if _, blocked := snapshot.values[model.BlockTypeDomain][domain]; blocked {
+			return true, nil
+		}
+		_, domain, ok = strings.Cut(domain, ".")
+	}
+	return false, nil
+}
+
+// IsIPBlocked checks a source IP against the hashed IPs and the CIDR ranges
+func (bs *BlocklistService) IsIPBlocked(ctx context.Context, sourceIP string) (blocked bool, err error) {
+	ctx, span := tracing.Start(ctx, "BlocklistService.IsIPBlocked")
+	defer func() { tracing.End(span, err) }()
+
+	if sourceIP == "" {
+		return false, nil
+	}
+	snapshot, err := bs.load(ctx)
+	if err != nil || snapshot == nil {
+		return false, err
+	}
+
+	addr, err := netip.ParseAddr(sourceIP)
+	if err != nil {
+		_, ok := snapshot.values[model.BlockTypeIP][hashIdentifier(sourceIP)]
+		return ok, nil
+	}
+	addr = addr.Unmap()
+	if _, ok := snapshot.values[model.BlockTypeIP][hashIdentifier(addr.String())]; ok {
+		return true, nil
+	}
+	for _, prefix := range snapshot.cidrs {
+		if prefix.Contains(addr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListEntries returns every active entry
+func (bs *BlocklistService) ListEntries(ctx context.Context) ([]model.BlocklistEntry, error) {
+	snapshot, err := bs.load(ctx)
+	if err != nil || snapshot == nil {
+		return []model.BlocklistEntry{}, err
+	}
+
+	entries := []model.BlocklistEntry{}
+	for _, entryType := range blocklistTypes {
+		for _, entry := range snapshot.values[entryType] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// AddEntry normalizes and stores an entry. Plain IP addresses are hashed before being stored.
+func (bs *BlocklistService) AddEntry(ctx context.Context, entry *model.BlocklistEntry) error {
+	if bs.storage == nil {
+		return errors.New("blocklist is not configured")
+	}
+
+	value, err := normalizeBlocklistValue(entry.Type, entry.Value)
+	if err != nil {
+		return err
+	}
+	entry.Value = value
+	entry.CreatedAt = bs.now()
+	if entry.ExpiresAt != nil && !entry.ExpiresAt.After(entry.CreatedAt) {
+		return fmt.Errorf("%w: expiry is in the past", ErrInvalidBlocklistEntry)
+	}
+
+	if err := bs.storage.PutBlocklistEntry(ctx, entry); err != nil {
+		return err
+	}
+	bs.invalidate()
+	return nil
+}
+
+// RemoveEntry deletes an entry, the value is normalized like in AddEntry
+func (bs *BlocklistService) RemoveEntry(ctx context.Context, entryType, value string) error {
+	if bs.storage == nil {
+		return errors.New("blocklist is not configured")
+	}
+
+	normalized, err := normalizeBlocklistValue(entryType, value)
+	if err != nil && entryType == model.BlockTypeDomain {
+		// Domains added before they were validated can still be removed
+		normalized, err = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "@"), nil
+	}
+	if err != nil {
+		return err
+	}
+	value = normalized
+
+	if err := bs.storage.DeleteBlocklistEntry(ctx, entryType, value); err != nil {
+		return err
+	}
+	bs.invalidate()
+	return nil
+}
+
+func normalizeBlocklistValue(entryType, value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "", fmt.Errorf("%w: empty value", ErrInvalidBlocklistEntry)
+	}
+
+	switch entryType {
+	case model.BlockTypeEmail:
+		if !isValidEmail(value) {
+			return "", fmt.Errorf("%w: invalid email %q", ErrInvalidBlocklistEntry, value)
+		}
+	case model.BlockTypeDomain:
+		// Parent domains are blocked too, so a public suffix like com or co.uk would block
+		// every sender under it
+		value = strings.TrimPrefix(value, "@")
+		if !isValidHostname(value) {
+			return "", fmt.Errorf("%w: invalid domain %q", ErrInvalidBlocklistEntry, value)
+		}
+		if _, err := publicsuffix.EffectiveTLDPlusOne(value); err != nil {
+			return "", fmt.Errorf("%w: %q is a public suffix", ErrInvalidBlocklistEntry, value)
+		}
+	case model.BlockTypeIP:
+		if sha256HexPattern.MatchString(value) {
+			return value, nil
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid IP %q", ErrInvalidBlocklistEntry, value)
+		}
+		return hashIdentifier(addr.Unmap().String()), nil
+	case model.BlockTypeCIDR:
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid CIDR %q", ErrInvalidBlocklistEntry, value)
+		}
+		return prefix.Masked().String(), nil
+	default:
+		return "", fmt.Errorf("%w: unknown type %q", ErrInvalidBlocklistEntry, entryType)
+	}
+	return value, nil
+}
+
+func (bs *BlocklistService) invalidate() {
+	bs.mu.Lock()
+	bs.reloadAt = time.Time{}
+	bs.generation++
+	bs.mu.Unlock()
+}
+
+// load returns the cached blocklist, reloading it from storage once the cache is stale
+// or an entry has expired. When a reload fails the previous blocklist is kept, and storage
+// isn't retried before the cache TTL has passed again.
+func (bs *BlocklistService) load(ctx context.Context) (*blocklistSnapshot, error) {
+	if bs.storage == nil {
+		return nil, nil
+	}
+
+	bs.mu.Lock()
+	now := bs.now()
+	// Callers racing the first load load too, rather than see an empty blocklist
+	if now.Before(bs.reloadAt) && (bs.snapshot != nil || bs.loadErr != nil) {
+		snapshot, err := bs.snapshot, bs.loadErr
+		bs.mu.Unlock()
+		if snapshot != nil {
+			return snapshot, nil
+		}
+		return nil, err
+	}
+	// Other callers keep the current snapshot while this one reloads it
+	bs.reloadAt = now.Add(bs.cacheTTL)
+	generation := bs.generation
+	bs.mu.Unlock()
+
+	snapshot, err := bs.fetch(ctx, now)
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if err != nil {
+		bs.loadErr = err
+		if bs.snapshot == nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "Error reloading blocklist, keeping the previous one", "error", err)
+		return bs.snapshot, nil
+	}
+
+	bs.snapshot = snapshot
+	bs.loadErr = nil
+	if generation != bs.generation {
+		// Changed during the load, the next call reloads
+		bs.reloadAt = time.Time{}
+	} else if !snapshot.expiresAt.IsZero() && snapshot.expiresAt.Before(bs.reloadAt) {
+		bs.reloadAt = snapshot.expiresAt
+	}
+	return snapshot, nil
+}
+
+// fetch reads every entry that hasn't expired by now from storage
+func (bs *BlocklistService) fetch(ctx context.Context, now time.Time) (*blocklistSnapshot, error) {
+	snapshot := &blocklistSnapshot{values: make(map[string]map[string]model.BlocklistEntry)}
+	for _, entryType := range blocklistTypes {
+		entries, err := bs.storage.ListBlocklistEntries(ctx, entryType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load blocklist: %w", err)
+		}
+
+		snapshot.values[entryType] = make(map[string]model.BlocklistEntry)
+		for _, entry := range entries {
+			// TTL deletion lags behind, so expired entries may still be stored
+			if entry.ExpiresAt != nil {
+				if !entry.ExpiresAt.After(now) {
+					continue
+				}
+				if snapshot.expiresAt.IsZero() || entry.ExpiresAt.Before(snapshot.expiresAt) {
+					snapshot.expiresAt = *entry.ExpiresAt
+				}
+			}
+			snapshot.values[entryType][entry.Value] = entry
+
+			if entryType == model.BlockTypeCIDR {
+				if prefix, err := netip.ParsePrefix(entry.Value); err == nil {
+					snapshot.cidrs = append(snapshot.cidrs, prefix)
+				}
+			}
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBlocklistStorage struct {
+	mock.Mock
+}
+
+func (m *MockBlocklistStorage) ListBlocklistEntries(ctx context.Context, entryType string) ([]model.BlocklistEntry, error) {
+	args := m.Called(ctx, entryType)
+	return args.Get(0).([]model.BlocklistEntry), args.Error(1)
+}
+
+func (m *MockBlocklistStorage) PutBlocklistEntry(ctx context.Context, entry *model.BlocklistEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockBlocklistStorage) DeleteBlocklistEntry(ctx context.Context, entryType, value string) error {
+	args := m.Called(ctx, entryType, value)
+	return args.Error(0)
+}
+
+func newTestBlocklist(entries map[string][]model.BlocklistEntry, now time.Time) (*BlocklistService, *MockBlocklistStorage) {
+	mockStorage := new(MockBlocklistStorage)
+	for _, entryType := range blocklistTypes {
+		mockStorage.On("ListBlocklistEntries", mock.Anything, entryType).Return(entries[entryType], nil)
+	}
+	bs := NewBlocklistService(mockStorage, time.Minute)
+	bs.now = func() time.Time { return now }
+	return bs, mockStorage
+}
+
+func TestBlocklistService_IsEmailBlocked(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs, _ := newTestBlocklist(map[string][]model.BlocklistEntry{
+		model.BlockTypeEmail:  {{Type: model.BlockTypeEmail, Value: "spammer@example.com"}},
+		model.BlockTypeDomain: {{Type: model.BlockTypeDomain, Value: "spam.test"}},
+	}, now)
+
+	tests := []struct {
+		name    string
+		email   string
+		blocked bool
+	}{
+		{"exact email", "spammer@example.com", true},
+		{"email is case insensitive", "Spammer@Example.com", true},
+		{"other email on same domain", "friend@example.com", false},
+		{"blocked domain", "anyone@spam.test", true},
+		{"subdomain of blocked domain", "anyone@mail.spam.test", true},
+		{"unrelated domain", "anyone@notspam.test", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, err := bs.IsEmailBlocked(context.Background(), tt.email)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.blocked, blocked)
+		})
+	}
+}
+
+func TestBlocklistService_IsIPBlocked(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs, _ := newTestBlocklist(map[string][]model.BlocklistEntry{
+		model.BlockTypeIP:   {{Type: model.BlockTypeIP, Value: hashIdentifier("203.0.113.7")}},
+		model.BlockTypeCIDR: {{Type: model.BlockTypeCIDR, Value: "198.51.100.0/24"}},
+	}, now)
+
+	tests := []struct {
+		name    string
+		ip      string
+		blocked bool
+	}{
+		{"hashed IP", "203.0.113.7", true},
+		{"IPv4-mapped hashed IP", "::ffff:203.0.113.7", true},
+		{"inside CIDR", "198.51.100.42", true},
+		{"outside CIDR", "198.51.101.1", false},
+		{"empty IP", "", false},
+		{"unparseable IP", "not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, err := bs.IsIPBlocked(context.Background(), tt.ip)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.blocked, blocked)
+		})
+	}
+}
+
+func TestBlocklistService_SkipsExpiredEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Minute)
+	soon := now.Add(30 * time.Second)
+	bs, _ := newTestBlocklist(map[string][]model.BlocklistEntry{
+		model.BlockTypeEmail: {
+			{Type: model.BlockTypeEmail, Value: "old@example.com", ExpiresAt: &expired},
+			{Type: model.BlockTypeEmail, Value: "soon@example.com", ExpiresAt: &soon},
+		},
+	}, now)
+
+	blocked, err := bs.IsEmailBlocked(context.Background(), "old@example.com")
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	blocked, err = bs.IsEmailBlocked(context.Background(), "soon@example.com")
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// The entry expires before the cache TTL, which forces a reload
+	bs.now = func() time.Time { return now.Add(45 * time.Second) }
+	blocked, err = bs.IsEmailBlocked(context.Background(), "soon@example.com")
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
+
+func TestBlocklistService_CachesEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs, mockStorage := newTestBlocklist(map[string][]model.BlocklistEntry{}, now)
+
+	for i := 0; i < 3; i++ {
+		_, err := bs.IsEmailBlocked(context.Background(), "user@example.com")
+		assert.NoError(t, err)
+	}
+	mockStorage.AssertNumberOfCalls(t, "ListBlocklistEntries", len(blocklistTypes))
+
+	bs.now = func() time.Time { return now.Add(2 * time.Minute) }
+	_, err := bs.IsEmailBlocked(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+	mockStorage.AssertNumberOfCalls(t, "ListBlocklistEntries", 2*len(blocklistTypes))
+}
+
+func TestBlocklistService_AddEntry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		entry     model.BlocklistEntry
+		wantValue string
+		wantErr   bool
+	}{
+		{"email is lowercased", model.BlocklistEntry{Type: model.BlockTypeEmail, Value: " Spammer@Example.com "}, "spammer@example.com", false},
+		{"domain drops leading @", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "@Spam.test"}, "spam.test", false},
+		{"IP is hashed", model.BlocklistEntry{Type: model.BlockTypeIP, Value: "203.0.113.7"}, hashIdentifier("203.0.113.7"), false},
+		{"hashed IP is kept", model.BlocklistEntry{Type: model.BlockTypeIP, Value: hashIdentifier("203.0.113.7")}, hashIdentifier("203.0.113.7"), false},
+		{"CIDR is masked", model.BlocklistEntry{Type: model.BlockTypeCIDR, Value: "198.51.100.9/24"}, "198.51.100.0/24", false},
+		{"subdomain", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "mail.spam.co.uk"}, "mail.spam.co.uk", false},
+		{"top-level domain", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "com"}, "", true},
+		{"public suffix", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "co.uk"}, "", true},
+		{"domain with spaces", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "spam test.com"}, "", true},
+		{"domain with @", model.BlocklistEntry{Type: model.BlockTypeDomain, Value: "bot@spam.test"}, "", true},
+		{"invalid email", model.BlocklistEntry{Type: model.BlockTypeEmail, Value: "not-an-email"}, "", true},
+		{"invalid IP", model.BlocklistEntry{Type: model.BlockTypeIP, Value: "999.1.1.1"}, "", true},
+		{"invalid CIDR", model.BlocklistEntry{Type: model.BlockTypeCIDR, Value: "10.0.0.0/99"}, "", true},
+		{"unknown type", model.BlocklistEntry{Type: "phone", Value: "555"}, "", true},
+		{"expiry in the past", model.BlocklistEntry{Type: model.BlockTypeEmail, Value: "a@example.com", ExpiresAt: &past}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockBlocklistStorage)
+			mockStorage.On("PutBlocklistEntry", mock.Anything, mock.Anything).Return(nil)
+			bs := NewBlocklistService(mockStorage, time.Minute)
+			bs.now = func() time.Time { return now }
+
+			entry := tt.entry
+			err := bs.AddEntry(context.Background(), &entry)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidBlocklistEntry)
+				mockStorage.AssertNotCalled(t, "PutBlocklistEntry", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, entry.Value)
+			assert.Equal(t, now, entry.CreatedAt)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlocklistService_RemoveEntry(t *testing.T) {
+	mockStorage := new(MockBlocklistStorage)
+	mockStorage.On("DeleteBlocklistEntry", mock.Anything, model.BlockTypeIP, hashIdentifier("203.0.113.7")).Return(nil)
+	mockStorage.On("DeleteBlocklistEntry", mock.Anything, model.BlockTypeDomain, "com").Return(nil)
+	bs := NewBlocklistService(mockStorage, time.Minute)
+
+	assert.NoError(t, bs.RemoveEntry(context.Background(), model.BlockTypeIP, "203.0.113.7"))
+	assert.NoError(t, bs.RemoveEntry(context.Background(), model.BlockTypeDomain, "@COM"), "domains stored before validation can be removed")
+	assert.ErrorIs(t, bs.RemoveEntry(context.Background(), model.BlockTypeIP, "not-an-ip"), ErrInvalidBlocklistEntry)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestBlocklistService_AddEntryInvalidatesCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs, mockStorage := newTestBlocklist(map[string][]model.BlocklistEntry{}, now)
+	mockStorage.On("PutBlocklistEntry", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := bs.IsEmailBlocked(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+	err = bs.AddEntry(context.Background(), &model.BlocklistEntry{Type: model.BlockTypeEmail, Value: "user@example.com"})
+	assert.NoError(t, err)
+	_, err = bs.IsEmailBlocked(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+
+	mockStorage.AssertNumberOfCalls(t, "ListBlocklistEntries", 2*len(blocklistTypes))
+}
+
+func TestBlocklistService_NilStorage(t *testing.T) {
+	bs := NewBlocklistService(nil, time.Minute)
+
+	blocked, err := bs.IsEmailBlocked(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	blocked, err = bs.IsIPBlocked(context.Background(), "203.0.113.7")
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	err = bs.AddEntry(context.Background(), &model.BlocklistEntry{Type: model.BlockTypeEmail, Value: "user@example.com"})
+	assert.Error(t, err)
+}
+
+func TestBlocklistService_StorageError(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockStorage := new(MockBlocklistStorage)
+	mockStorage.On("ListBlocklistEntries", mock.Anything, mock.Anything).Return([]model.BlocklistEntry(nil), errors.New("boom"))
+	bs := NewBlocklistService(mockStorage, time.Minute)
+	bs.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		blocked, err := bs.IsEmailBlocked(context.Background(), "user@example.com")
+		assert.Error(t, err)
+		assert.False(t, blocked)
+	}
+	mockStorage.AssertNumberOfCalls(t, "ListBlocklistEntries", 1)
+}
+
+func TestBlocklistService_ReloadErrorKeepsBlocklist(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs, mockStorage := newTestBlocklist(map[string][]model.BlocklistEntry{
+		model.BlockTypeDomain: {{Type: model.BlockTypeDomain, Value: "spam.test"}},
+	}, now)
+
+	blocked, err := bs.IsEmailBlocked(context.Background(), "bot@spam.test")
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	failing := new(MockBlocklistStorage)
+	failing.On("ListBlocklistEntries", mock.Anything, mock.Anything).Return([]model.BlocklistEntry(nil), errors.New("throttled"))
+	bs.storage = failing
+	bs.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+	for i := 0; i < 3; i++ {
+		blocked, err = bs.IsEmailBlocked(context.Background(), "bot@spam.test")
+		assert.NoError(t, err)
+		assert.True(t, blocked, "the previous blocklist is kept")
+	}
+	failing.AssertNumberOfCalls(t, "ListBlocklistEntries", 1)
+
+	// Storage is retried once the cache TTL has passed again
+	bs.storage = mockStorage
+	bs.now = func() time.Time { return now.Add(3 * time.Minute) }
+	_, err = bs.IsEmailBlocked(context.Background(), "bot@spam.test")
+	assert.NoError(t, err)
+	mockStorage.AssertNumberOfCalls(t, "ListBlocklistEntries", 2*len(blocklistTypes))
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"main/internal/model"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed data/crawler_ranges.txt
+var defaultCrawlerRanges string
+
+// userAgentPatterns match crawlers, uptime monitors and HTTP libraries
+var userAgentPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver`),
+	regexp.MustCompile(`(?i)uptime|pingdom|monitor|statuscake|site24x7|checkly`),
+	regexp.MustCompile(`(?i)headless|phantomjs|puppeteer|playwright|selenium`),
+	regexp.MustCompile(`(?i)^(curl|wget|python|go-http-client|java|okhttp|axios|node-fetch|libwww-perl)`),
+	regexp.MustCompile(`(?i)facebookexternalhit|embedly`),
+}
+
+// ClientInfo holds the request attributes used to tell browsers from bots
+type ClientInfo struct {
+	UserAgent      string
+	SourceIP       string
+	AcceptLanguage string
+}
+
+type BotDetector struct {
+	crawlerRanges []netip.Prefix
+	minSessionAge time.Duration
+	now           func() time.Time
+}
+
+// NewBotDetector creates a detector checking the given crawler IP ranges.
+// Requests arriving less than minSessionAge after their session was created are
+// too fast to come from a browser rendering the page; 0 disables the check.
+func NewBotDetector(crawlerRanges []netip.Prefix, minSessionAge time.Duration) *BotDetector {
+	return &BotDetector{
+		crawlerRanges: crawlerRanges,
+		minSessionAge: minSessionAge,
+		now:           time.Now,
+	}
+}
+
+// LoadCrawlerRanges returns the built-in crawler ranges plus the ones listed in path, if set
+func LoadCrawlerRanges(path string) ([]netip.Prefix, error) {
+	ranges, err := parseCrawlerRanges(strings.NewReader(defaultCrawlerRanges))
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return ranges, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open crawler ranges file: %w", err)
+	}
+	defer f.Close()
+
+	extra, err := parseCrawlerRanges(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(ranges, extra...), nil
+}
+
+// parseCrawlerRanges reads one CIDR per line, ignoring blank lines and # comments
+func parseCrawlerRanges(r io.Reader) ([]netip.Prefix, error) {
+	var ranges []netip.Prefix
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid crawler range %q: %w", line, err)
+		}
+		ranges = append(ranges, prefix)
+	}
+	return ranges, scanner.Err()
+}
+
+// Classify reports whether the request looks like it comes from a bot, and why
+func (bd *BotDetector) Classify(client ClientInfo, session *model.UserSession) (bool, string) {
+	if client.UserAgent == "" {
+		return true, "missing_user_agent"
+	}
+
+	for _, pattern := range userAgentPatterns {
+		if pattern.MatchString(client.UserAgent) {
+			return true, "user_agent"
+		}
+	}
+
+	if addr, err := netip.ParseAddr(client.SourceIP); err == nil {
+		addr = addr.Unmap()
+		for _, prefix := range bd.crawlerRanges {
+			if prefix.Contains(addr) {
+				return true, "crawler_ip"
+			}
+		}
+	}
+
+	// Every browser sends Accept-Language on fetch requests, most HTTP clients don't
+	if client.AcceptLanguage == "" {
+		return true, "missing_browser_headers"
+	}
+
+	if bd.minSessionAge > 0 && session != nil && !session.CreatedAt.IsZero() &&
+		bd.now().Sub(session.CreatedAt) < bd.minSessionAge {
+		return true, "request_timing"
+	}
+
+	return false, ""
+}
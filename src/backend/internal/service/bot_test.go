@@ -0,0 +1,100 @@
+package service
+
+import (
+	"main/internal/model"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotDetector_Classify(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	browserUA := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+	oldSession := &model.UserSession{SessionID: "test-session", CreatedAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name           string
+		client         ClientInfo
+		session        *model.UserSession
+		expectedBot    bool
+		expectedReason string
+	}{
+		{
+			name:        "regular browser",
+			client:      ClientInfo{UserAgent: browserUA, SourceIP: "203.0.113.7", AcceptLanguage: "en-US"},
+			session:     oldSession,
+			expectedBot: false,
+		},
+		{
+			name:           "crawler user agent",
+			client:         ClientInfo{UserAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)", SourceIP: "203.0.113.7", AcceptLanguage: "en-US"},
+			session:        oldSession,
+			expectedBot:    true,
+			expectedReason: "user_agent",
+		},
+		{
+			name:           "uptime monitor",
+			client:         ClientInfo{UserAgent: "UptimeRobot/2.0", SourceIP: "203.0.113.7", AcceptLanguage: "en-US"},
+			session:        oldSession,
+			expectedBot:    true,
+			expectedReason: "user_agent",
+		},
+		{
+			name:           "missing user agent",
+			client:         ClientInfo{SourceIP: "203.0.113.7", AcceptLanguage: "en-US"},
+			session:        oldSession,
+			expectedBot:    true,
+			expectedReason: "missing_user_agent",
+		},
+		{
+			name:           "crawler ip range",
+			client:         ClientInfo{UserAgent: browserUA, SourceIP: "66.249.66.1", AcceptLanguage: "en-US"},
+			session:        oldSession,
+			expectedBot:    true,
+			expectedReason: "crawler_ip",
+		},
+		{
+			name:           "missing accept-language",
+			client:         ClientInfo{UserAgent: browserUA, SourceIP: "203.0.113.7"},
+			session:        oldSession,
+			expectedBot:    true,
+			expectedReason: "missing_browser_headers",
+		},
+		{
+			name:           "request right after session creation",
+			client:         ClientInfo{UserAgent: browserUA, SourceIP: "203.0.113.7", AcceptLanguage: "en-US"},
+			session:        &model.UserSession{SessionID: "test-session", CreatedAt: now.Add(-10 * time.Millisecond)},
+			expectedBot:    true,
+			expectedReason: "request_timing",
+		},
+	}
+
+	ranges, err := parseCrawlerRanges(strings.NewReader(defaultCrawlerRanges))
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewBotDetector(ranges, 50*time.Millisecond)
+			detector.now = func() time.Time { return now }
+
+			isBot, reason := detector.Classify(tt.client, tt.session)
+			assert.Equal(t, tt.expectedBot, isBot)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestParseCrawlerRanges(t *testing.T) {
+	ranges, err := parseCrawlerRanges(strings.NewReader("# comment\n\n192.0.2.0/24\n2001:db8::/32\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, ranges)
+
+	_, err = parseCrawlerRanges(strings.NewReader("not-a-cidr\n"))
+	assert.Error(t, err)
+}
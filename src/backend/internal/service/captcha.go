@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/tracing"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	CaptchaRecaptchaV2 = "recaptcha_v2"
+	CaptchaRecaptchaV3 = "recaptcha_v3"
+	CaptchaHCaptcha    = "hcaptcha"
+	CaptchaTurnstile   = "turnstile"
+)
+
+var captchaVerifyURLs = map[string]string{
+	CaptchaRecaptchaV2: "https://www.google.com/recaptcha/api/siteverify",
+	CaptchaRecaptchaV3: "https://www.google.com/recaptcha/api/siteverify",
+	CaptchaHCaptcha:    "https://api.hcaptcha.com/siteverify",
+	CaptchaTurnstile:   "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// ErrCaptchaUnavailable is returned when the CAPTCHA provider can't be reached or doesn't
+// answer properly, so the token couldn't be checked either way
+var ErrCaptchaUnavailable = errors.New("CAPTCHA verification unavailable")
+
+// CaptchaResult is the outcome of a CAPTCHA verification.
+// Reason explains why a token was rejected when Success is false.
+type CaptchaResult struct {
+	Success bool
+	Score   float64
+	Reason  string
+}
+
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (*CaptchaResult, error)
+}
+
+// siteVerifyCaptcha verifies tokens against a siteverify endpoint. reCAPTCHA, hCaptcha and
+// Turnstile share the same request and response format, they only differ in what they report.
+type siteVerifyCaptcha struct {
+	client           *http.Client
+	timeout          time.Duration
+	verifyURL        string
+	secret           config.Secret
+	checkScore       bool
+	minScore         float64
+	expectedAction   string
+	expectedHostname string
+	maxAge           time.Duration
+	now              func() time.Time
+}
+
+// NewCaptchaVerifier returns the verifier for the provider selected in config.
+// verifyURL overrides the provider's siteverify endpoint when set, e.g. to point at a local fake.
+func NewCaptchaVerifier(cfg *config.Config, client *http.Client, verifyURL string) (CaptchaVerifier, error) {
+	defaultURL, ok := captchaVerifyURLs[cfg.CaptchaProvider]
+	if !ok {
+		return nil, fmt.Errorf("unknown CAPTCHA provider: %s", cfg.CaptchaProvider)
+	}
+	if verifyURL == "" {
+		verifyURL = defaultURL
+	}
+
+	verifier := &siteVerifyCaptcha{
+		client:           client,
+		timeout:          cfg.CaptchaTimeout,
+		verifyURL:        verifyURL,
+		secret:           cfg.Secret("CAPTCHA_SECRET_KEY"),
+		expectedHostname: cfg.CaptchaExpectedHostname,
+		maxAge:           cfg.CaptchaMaxAge,
+		now:              time.Now,
+	}
+
+	switch cfg.CaptchaProvider {
+	case CaptchaRecaptchaV3:
+		// Only v3 scores requests, v2 and the others are pass/fail challenges
+		verifier.checkScore = true
+		verifier.minScore = cfg.CaptchaMinScore
+		verifier.expectedAction = cfg.CaptchaExpectedAction
+	case CaptchaTurnstile:
+		verifier.expectedAction = cfg.CaptchaExpectedAction
+	}
+
+	return verifier, nil
+}
+
+func (v *siteVerifyCaptcha) Verify(ctx context.Context, token, remoteIP string) (result *CaptchaResult, err error) {
+	ctx, span := tracing.Start(ctx, "Captcha.Verify")
+	defer func() { tracing.End(span, err) }()
+
+	data := url.Values{}
+	data.Set("secret", v.secret(ctx))
+	data.Set("response", token)
+	if remoteIP != "" {
+		data.Set("remoteip", remoteIP)
+	}
+
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed CAPTCHA API request: %v", ErrCaptchaUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: CAPTCHA API returned status %d", ErrCaptchaUnavailable, res.StatusCode)
+	}
+
+	var captchaRes model.RecaptchaResponse
+	if err = json.NewDecoder(res.Body).Decode(&captchaRes); err != nil {
+		return nil, fmt.Errorf("%w: invalid CAPTCHA API response: %v", ErrCaptchaUnavailable, err)
+	}
+
+	return v.evaluate(&captchaRes), nil
+}
+
+// evaluate applies the configured checks to a siteverify response
+func (v *siteVerifyCaptcha) evaluate(res *model.RecaptchaResponse) *CaptchaResult {
+	result := &CaptchaResult{Score: res.Score}
+
+	if !res.Success {
+		result.Reason = "rejected by provider"
+		if len(res.ErrorCodes) > 0 {
+			result.Reason += ": " + strings.Join(res.ErrorCodes, ",")
+		}
+		return result
+	}
+
+	if v.checkScore && res.Score < v.minScore {
+		result.Reason = fmt.Sprintf("score %.1f below %.1f", res.Score, v.minScore)
+		return result
+	}
+
+	if v.expectedAction != "" && res.Action != v.expectedAction {
+		result.Reason = fmt.Sprintf("unexpected action %q", res.Action)
+		return result
+	}
+
+	if v.expectedHostname != "" && res.Hostname != v.expectedHostname {
+		result.Reason = fmt.Sprintf("unexpected hostname %q", res.Hostname)
+		return result
+	}
+
+	if v.maxAge > 0 {
+		challengeTS, err := time.Parse(time.RFC3339, res.ChallengeTS)
+		if err != nil {
+			result.Reason = "missing or invalid challenge timestamp"
+			return result
+		}
+		if v.now().Sub(challengeTS) > v.maxAge {
+			result.Reason = "challenge expired"
+			return result
+		}
+	}
+
+	result.Success = true
+	return result
+}
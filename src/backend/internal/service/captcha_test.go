@@ -0,0 +1,105 @@
+package service
+
+import (
+	"main/internal/config"
+	"main/internal/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteVerifyCaptcha_Evaluate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	fresh := now.Add(-30 * time.Second).Format(time.RFC3339)
+
+	tests := []struct {
+		name           string
+		provider       string
+		response       model.RecaptchaResponse
+		expectedResult bool
+		expectedReason string
+	}{
+		{
+			name:           "recaptcha v3 success",
+			provider:       CaptchaRecaptchaV3,
+			response:       model.RecaptchaResponse{Success: true, Score: 0.9, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedResult: true,
+		},
+		{
+			name:           "recaptcha v3 low score",
+			provider:       CaptchaRecaptchaV3,
+			response:       model.RecaptchaResponse{Success: true, Score: 0.1, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedResult: false,
+			expectedReason: "score 0.1 below 0.5",
+		},
+		{
+			name:           "recaptcha v3 wrong action",
+			provider:       CaptchaRecaptchaV3,
+			response:       model.RecaptchaResponse{Success: true, Score: 0.9, Action: "login", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedResult: false,
+			expectedReason: `unexpected action "login"`,
+		},
+		{
+			name:           "recaptcha v2 ignores score",
+			provider:       CaptchaRecaptchaV2,
+			response:       model.RecaptchaResponse{Success: true, Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedResult: true,
+		},
+		{
+			name:           "hcaptcha wrong hostname",
+			provider:       CaptchaHCaptcha,
+			response:       model.RecaptchaResponse{Success: true, Hostname: "evil.example.com", ChallengeTS: fresh},
+			expectedResult: false,
+			expectedReason: `unexpected hostname "evil.example.com"`,
+		},
+		{
+			name:           "turnstile expired challenge",
+			provider:       CaptchaTurnstile,
+			response:       model.RecaptchaResponse{Success: true, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: now.Add(-time.Hour).Format(time.RFC3339)},
+			expectedResult: false,
+			expectedReason: "challenge expired",
+		},
+		{
+			name:           "missing challenge timestamp",
+			provider:       CaptchaTurnstile,
+			response:       model.RecaptchaResponse{Success: true, Action: "contact", Hostname: "www.pwnph0fun.com"},
+			expectedResult: false,
+			expectedReason: "missing or invalid challenge timestamp",
+		},
+		{
+			name:           "provider error codes",
+			provider:       CaptchaRecaptchaV3,
+			response:       model.RecaptchaResponse{Success: false, ErrorCodes: []string{"invalid-input-response"}},
+			expectedResult: false,
+			expectedReason: "rejected by provider: invalid-input-response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				CaptchaProvider:         tt.provider,
+				CaptchaMinScore:         0.5,
+				CaptchaExpectedAction:   "contact",
+				CaptchaExpectedHostname: "www.pwnph0fun.com",
+				CaptchaMaxAge:           5 * time.Minute,
+			}
+			verifier, err := NewCaptchaVerifier(cfg, http.DefaultClient, "")
+			assert.NoError(t, err)
+
+			v := verifier.(*siteVerifyCaptcha)
+			v.now = func() time.Time { return now }
+			result := v.evaluate(&tt.response)
+
+			assert.Equal(t, tt.expectedResult, result.Success)
+			assert.Equal(t, tt.expectedReason, result.Reason)
+		})
+	}
+}
+
+func TestNewCaptchaVerifier_UnknownProvider(t *testing.T) {
+	_, err := NewCaptchaVerifier(&config.Config{CaptchaProvider: "unknown"}, http.DefaultClient, "")
+	assert.Error(t, err)
+}
@@ -2,54 +2,144 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"main/internal/config"
+	"main/internal/metrics"
 	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
-	"net/url"
-	"strings"
+	"time"
 )
 
+// ErrCaptchaFailed is returned when a contact request doesn't pass CAPTCHA verification
+var ErrCaptchaFailed = errors.New("failed CAPTCHA")
+
 type ContactService struct {
-	config *config.Config
+	config    *config.Config
+	inbox     storage.InboxStorageInterface
+	spam      *SpamScorer
+	blocklist *BlocklistService
+	verifier  CaptchaVerifier
+}
+
+// NewContactService creates the service with the HTTP client used for CAPTCHA verification.
+// verifyURL overrides the CAPTCHA provider's siteverify endpoint when set.
+// Messages aren't spam scored when spam is nil, and senders aren't checked when blocklist is nil.
+func NewContactService(cfg *config.Config, inbox storage.InboxStorageInterface, spam *SpamScorer, blocklist *BlocklistService, client *http.Client, verifyURL string) (*ContactService, error) {
+	verifier, err := NewCaptchaVerifier(cfg, client, verifyURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ContactService{config: cfg, inbox: inbox, spam: spam, blocklist: blocklist, verifier: verifier}, nil
 }
 
-func NewContactService(cfg *config.Config) *ContactService {
-	return &ContactService{config: cfg}
+// IssueFormToken returns the token timing how long the contact form takes to fill in
+func (cs *ContactService) IssueFormToken(ctx context.Context, sessionID string) string {
+	if cs.spam == nil {
+		return ""
+	}
+	return cs.spam.IssueFormToken(ctx, sessionID)
 }
 
-func (cs *ContactService) verifyRecaptcha(token string) (bool, error) {
-	const verifyURL = "https://www.google.com/recaptcha/api/siteverify"
-	data := url.Values{}
-	data.Set("secret", cs.config.RecaptchaSecretKey)
-	data.Set("response", token)
+// ProcessContactRequest rejects blocklisted senders, verifies the CAPTCHA, scores the message for spam and stores it in
+// the inbox. Messages scoring above the spam threshold are stored as quarantined.
+// The stored message is returned so that notifications can reference it.
+func (cs *ContactService) ProcessContactRequest(ctx context.Context, contactReq *model.ContactRequest, sessionID, sourceIP string) (msg *model.ContactMessage, err error) {
+	ctx, span := tracing.Start(ctx, "ContactService.ProcessContactRequest")
+	defer func() { tracing.End(span, err) }()
 
-	res, err := http.Post(verifyURL, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+	if err := cs.checkBlocklist(ctx, contactReq.Email, sourceIP); err != nil {
+		return nil, err
+	}
+
+	result, err := cs.verifier.Verify(ctx, contactReq.Recaptcha, sourceIP)
+	cs.recordCaptcha(result, err)
 	if err != nil {
-		return false, fmt.Errorf("failed reCAPTCHA API request: %v", err)
+		return nil, err
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("%w: %s", ErrCaptchaFailed, result.Reason)
 	}
-	defer res.Body.Close()
 
-	var recaptchaRes model.RecaptchaResponse
-	if err = json.NewDecoder(res.Body).Decode(&recaptchaRes); err != nil {
-		return false, err
+	msg = &model.ContactMessage{
+		ID:           cs.generateMessageID(),
+		CreatedAt:    time.Now(),
+		Name:         contactReq.Name,
+		Email:        contactReq.Email,
+		Message:      contactReq.Message,
+		CaptchaScore: result.Score,
+		Status:       model.ContactStatusNew,
+	}
+	if sourceIP != "" {
+		msg.SourceIPHash = hashIdentifier(sourceIP)
 	}
 
-	const minScore = 0.5
-	if !recaptchaRes.Success || recaptchaRes.Score < minScore || recaptchaRes.Action != "contact" {
-		return false, nil
+	if cs.spam != nil {
+		verdict := cs.spam.Score(ctx, &SpamInput{Request: contactReq, SessionID: sessionID, CaptchaScore: result.Score})
+		msg.SpamScore = verdict.Score
+		for _, signal := range verdict.Signals {
+			msg.SpamSignals = append(msg.SpamSignals, signal.Check)
+		}
+		if verdict.Quarantine {
+			msg.Status = model.ContactStatusQuarantined
+		}
 	}
-	return true, nil
+
+	if err := cs.inbox.CreateContactMessage(ctx, msg); err != nil {
+		return nil, fmt.Errorf("failed to store contact message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// recordCaptcha emits the verification outcome and, when the provider answered, the score
+func (cs *ContactService) recordCaptcha(result *CaptchaResult, err error) {
+	dims := metrics.Dimensions{"Provider": cs.config.CaptchaProvider}
+	switch {
+	case err != nil:
+		dims["Result"] = "error"
+		metrics.Emit(dims, metrics.Count("CaptchaVerifications", 1))
+		return
+	case result.Success:
+		dims["Result"] = "pass"
+	default:
+		dims["Result"] = "fail"
+	}
+	metrics.Emit(dims, metrics.Count("CaptchaVerifications", 1), metrics.Value("CaptchaScore", result.Score))
 }
 
-func (cs *ContactService) ProcessContactRequest(ctx context.Context, contactReq *model.ContactRequest) error {
-	good, err := cs.verifyRecaptcha(contactReq.Recaptcha)
+// checkBlocklist returns ErrBlocked for blocklisted senders. Like the rate limiter's blocklist
+// check, it fails open so a storage error doesn't turn away real messages.
+func (cs *ContactService) checkBlocklist(ctx context.Context, email, sourceIP string) error {
+	if cs.blocklist == nil {
+		return nil
+	}
+
+	blocked, err := cs.blocklist.IsEmailBlocked(ctx, email)
 	if err != nil {
-		return err
+		slog.ErrorContext(ctx, "Error checking blocklist", "error", err)
+		return nil
+	}
+	if !blocked {
+		blocked, err = cs.blocklist.IsIPBlocked(ctx, sourceIP)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error checking blocklist", "error", err)
+			return nil
+		}
 	}
-	if !good {
-		return fmt.Errorf("failed reCAPTCHA: %v", err)
+	if blocked {
+		return ErrBlocked
 	}
 	return nil
 }
+
+func (cs *ContactService) generateMessageID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newFakeSiteVerify starts a local stand-in for a CAPTCHA siteverify endpoint.
+// It answers every request with response and fails the test on malformed requests.
+func newFakeSiteVerify(t *testing.T, response model.RecaptchaResponse) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		if r.PostForm.Get("secret") != "test-secret" || r.PostForm.Get("response") == "" {
+			t.Errorf("missing secret or response token: %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testContactConfig() *config.Config {
+	return &config.Config{
+		CaptchaProvider:         CaptchaRecaptchaV3,
+		CaptchaSecretKey:        "test-secret",
+		CaptchaMinScore:         0.5,
+		CaptchaExpectedAction:   "contact",
+		CaptchaExpectedHostname: "www.pwnph0fun.com",
+		CaptchaMaxAge:           5 * time.Minute,
+		CaptchaTimeout:          time.Second,
+	}
+}
+
+func TestContactService_ProcessContactRequest(t *testing.T) {
+	fresh := time.Now().Add(-10 * time.Second).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		response      model.RecaptchaResponse
+		mockSetup     func(*MockInboxStorage)
+		expectedError bool
+		errorMessage  string
+	}{
+		{
+			name:     "success",
+			response: model.RecaptchaResponse{Success: true, Score: 0.9, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			mockSetup: func(m *MockInboxStorage) {
+				m.On("CreateContactMessage", mock.Anything, mock.MatchedBy(func(msg *model.ContactMessage) bool {
+					return msg.ID != "" && msg.Email == "jane@example.com" && msg.CaptchaScore == 0.9 &&
+						msg.Status == model.ContactStatusNew && msg.SourceIPHash == hashIdentifier("203.0.113.7")
+				})).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:     "inbox storage error",
+			response: model.RecaptchaResponse{Success: true, Score: 0.9, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			mockSetup: func(m *MockInboxStorage) {
+				m.On("CreateContactMessage", mock.Anything, mock.Anything).Return(errors.New("put failed"))
+			},
+			expectedError: true,
+			errorMessage:  "failed to store contact message",
+		},
+		{
+			name:          "low score",
+			response:      model.RecaptchaResponse{Success: true, Score: 0.2, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedError: true,
+			errorMessage:  "score 0.2 below 0.5",
+		},
+		{
+			name:          "wrong action",
+			response:      model.RecaptchaResponse{Success: true, Score: 0.9, Action: "homepage", Hostname: "www.pwnph0fun.com", ChallengeTS: fresh},
+			expectedError: true,
+			errorMessage:  "unexpected action",
+		},
+		{
+			name:          "error codes",
+			response:      model.RecaptchaResponse{Success: false, ErrorCodes: []string{"timeout-or-duplicate"}},
+			expectedError: true,
+			errorMessage:  "timeout-or-duplicate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSiteVerify(t, tt.response)
+			mockInbox := &MockInboxStorage{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockInbox)
+			}
+
+			service, err := NewContactService(testContactConfig(), mockInbox, nil, nil, server.Client(), server.URL)
+			assert.NoError(t, err)
+
+			contactReq := &model.ContactRequest{Name: "Jane", Email: "jane@example.com", Message: "Hi", Recaptcha: "test-token"}
+			msg, err := service.ProcessContactRequest(context.Background(), contactReq, "test-session", "203.0.113.7")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Hi", msg.Message)
+			}
+
+			mockInbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_VerifyTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	cfg := testContactConfig()
+	cfg.CaptchaTimeout = 50 * time.Millisecond
+	service, err := NewContactService(cfg, &MockInboxStorage{}, nil, nil, server.Client(), server.URL)
+	assert.NoError(t, err)
+
+	contactReq := &model.ContactRequest{Recaptcha: "test-token"}
+	_, err = service.ProcessContactRequest(context.Background(), contactReq, "", "")
+	assert.ErrorIs(t, err, ErrCaptchaUnavailable)
+}
+
+func TestContactService_VerifyServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service, err := NewContactService(testContactConfig(), &MockInboxStorage{}, nil, nil, server.Client(), server.URL)
+	assert.NoError(t, err)
+
+	_, err = service.ProcessContactRequest(context.Background(), &model.ContactRequest{Recaptcha: "test-token"}, "", "")
+	assert.ErrorIs(t, err, ErrCaptchaUnavailable)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestContactService_BlockedSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("CAPTCHA should not be verified for blocked senders")
+	}))
+	defer server.Close()
+
+	blocklist, _ := newTestBlocklist(map[string][]model.BlocklistEntry{
+		model.BlockTypeDomain: {{Type: model.BlockTypeDomain, Value: "spam.test"}},
+	}, time.Now())
+
+	service, err := NewContactService(testContactConfig(), &MockInboxStorage{}, nil, blocklist, server.Client(), server.URL)
+	assert.NoError(t, err)
+
+	_, err = service.ProcessContactRequest(context.Background(), &model.ContactRequest{Email: "bot@spam.test", Recaptcha: "test-token"}, "test-session", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrBlocked)
+}
+
+func TestContactService_BlocklistErrorFailsOpen(t *testing.T) {
+	server := newFakeSiteVerify(t, model.RecaptchaResponse{Success: true, Score: 0.9, Action: "contact", Hostname: "www.pwnph0fun.com", ChallengeTS: time.Now().UTC().Format(time.RFC3339)})
+
+	mockStorage := new(MockBlocklistStorage)
+	mockStorage.On("ListBlocklistEntries", mock.Anything, mock.Anything).Return([]model.BlocklistEntry(nil), errors.New("throttled"))
+	blocklist := NewBlocklistService(mockStorage, time.Minute)
+
+	mockInbox := &MockInboxStorage{}
+	mockInbox.On("CreateContactMessage", mock.Anything, mock.Anything).Return(nil)
+
+	service, err := NewContactService(testContactConfig(), mockInbox, nil, blocklist, server.Client(), server.URL)
+	assert.NoError(t, err)
+
+	msg, err := service.ProcessContactRequest(context.Background(), &model.ContactRequest{Name: "Jane", Email: "jane@example.com", Message: "Hi", Recaptcha: "test-token"}, "test-session", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi", msg.Message)
+	mockInbox.AssertExpectations(t)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"main/internal/config"
+)
+
+type CSRFService struct {
+	secret config.Secret
+}
+
+func NewCSRFService(secret config.Secret) *CSRFService {
+	return &CSRFService{secret: secret}
+}
+
+// IssueToken returns the synchronizer token bound to a session.
+// The token is an HMAC of the session ID, so it doesn't need to be stored.
+func (cs *CSRFService) IssueToken(ctx context.Context, sessionID string) string {
+	return csrfToken(cs.secret(ctx), sessionID)
+}
+
+// ValidateToken checks that the token was issued for the given session
+func (cs *CSRFService) ValidateToken(ctx context.Context, sessionID, token string) bool {
+	secret := cs.secret(ctx)
+	if sessionID == "" || token == "" || secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(csrfToken(secret, sessionID)), []byte(token))
+}
+
+func csrfToken(secret, sessionID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(sessionID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"main/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFService_ValidateToken(t *testing.T) {
+	service := NewCSRFService(config.StaticSecret("test-secret"))
+	token := service.IssueToken(context.Background(), "test-session")
+
+	tests := []struct {
+		name      string
+		service   *CSRFService
+		sessionID string
+		token     string
+		expected  bool
+	}{
+		{
+			name:      "valid token",
+			service:   service,
+			sessionID: "test-session",
+			token:     token,
+			expected:  true,
+		},
+		{
+			name:      "token for another session",
+			service:   service,
+			sessionID: "other-session",
+			token:     token,
+			expected:  false,
+		},
+		{
+			name:      "missing token",
+			service:   service,
+			sessionID: "test-session",
+			token:     "",
+			expected:  false,
+		},
+		{
+			name:      "missing session",
+			service:   service,
+			sessionID: "",
+			token:     token,
+			expected:  false,
+		},
+		{
+			name:      "different secret",
+			service:   NewCSRFService(config.StaticSecret("other-secret")),
+			sessionID: "test-session",
+			token:     token,
+			expected:  false,
+		},
+		{
+			name:      "no secret configured",
+			service:   NewCSRFService(config.StaticSecret("")),
+			sessionID: "test-session",
+			token:     NewCSRFService(config.StaticSecret("")).IssueToken(context.Background(), "test-session"),
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.service.ValidateToken(context.Background(), tt.sessionID, tt.token))
+		})
+	}
+}
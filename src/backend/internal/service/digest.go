@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"time"
+)
+
+// Like notification modes
+const (
+	LikeNotificationsImmediate = "immediate"
+	LikeNotificationsDigest    = "digest"
+)
+
+// DigestSummary is the activity since the previous digest. Likes is the net change, so
+// like/unlike loops don't add up.
+type DigestSummary struct {
+	Likes         int
+	Visitors      int
+	TotalLikes    int
+	TotalVisitors int
+	Since         time.Time
+}
+
+// String summarizes the digest in one line, e.g. "14 new likes, 230 new visitors"
+func (d DigestSummary) String() string {
+	return fmt.Sprintf("%s, %d new %s", pluralize(d.Likes, "new like", "new likes"), d.Visitors, pluralWord(d.Visitors, "visitor", "visitors"))
+}
+
+func pluralize(n int, singular, plural string) string {
+	return fmt.Sprintf("%d %s", n, pluralWord(n, singular, plural))
+}
+
+func pluralWord(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// DigestService sends a periodic summary of likes and visits instead of one notification per like.
+// It runs from the scheduled Lambda invocation, the schedule is set on the EventBridge rule.
+type DigestService struct {
+	counters  storage.StorageInterface
+	state     storage.DigestStorageInterface
+	outbox    *OutboxService
+	flags     *FeatureFlagService
+	skipEmpty bool
+	now       func() time.Time
+}
+
+func NewDigestService(counters storage.StorageInterface, state storage.DigestStorageInterface, outbox *OutboxService, flags *FeatureFlagService, cfg *config.Config) *DigestService {
+	return &DigestService{
+		counters:  counters,
+		state:     state,
+		outbox:    outbox,
+		flags:     flags,
+		skipEmpty: cfg.DigestSkipEmpty,
+		now:       time.Now,
+	}
+}
+
+// SendDigest compares the counters with the totals at the previous digest and sends the difference.
+// The first run only records the current totals. Nothing is sent while the digest is turned
+// off, and the next digest covers the whole time since the previous one.
+func (ds *DigestService) SendDigest(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "DigestService.SendDigest")
+	defer func() { tracing.End(span, err) }()
+
+	if !ds.flags.Enabled(ctx, FlagDigest) {
+		slog.InfoContext(ctx, "Digest is turned off, skipping")
+		return nil
+	}
+
+	likes, err := ds.counters.GetCount(ctx, "likes")
+	if err != nil {
+		return fmt.Errorf("failed to get like count: %w", err)
+	}
+	visitors, err := ds.counters.GetCount(ctx, "visitors")
+	if err != nil {
+		return fmt.Errorf("failed to get visitor count: %w", err)
+	}
+
+	prev, err := ds.state.GetDigestState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get digest state: %w", err)
+	}
+
+	now := ds.now()
+	next := &model.DigestState{Likes: likes, Visitors: visitors, SentAt: now}
+	if prev == nil {
+		slog.InfoContext(ctx, "No previous digest, recording current totals")
+		return ds.state.SaveDigestState(ctx, next)
+	}
+
+	summary := DigestSummary{
+		Likes:         likes - prev.Likes,
+		Visitors:      visitors - prev.Visitors,
+		TotalLikes:    likes,
+		TotalVisitors: visitors,
+		Since:         prev.SentAt,
+	}
+	if ds.skipEmpty && summary.Likes == 0 && summary.Visitors == 0 {
+		slog.InfoContext(ctx, "Nothing new since the last digest, skipping", "since", prev.SentAt)
+		return ds.state.SaveDigestState(ctx, next)
+	}
+
+	err = ds.outbox.Notify(ctx, &model.NotificationPayload{
+		Type: "digest",
+		Data: map[string]any{
+			"likes":          summary.Likes,
+			"visitors":       summary.Visitors,
+			"total_likes":    summary.TotalLikes,
+			"total_visitors": summary.TotalVisitors,
+			"since":          summary.Since.Format(time.RFC3339),
+		},
+		Source:    "digest",
+		Timestamp: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+	return ds.state.SaveDigestState(ctx, next)
+}
+
+// digestFromPayload reads a digest back from a notification payload. Numbers come back
+// as float64 once the payload has been through JSON or the outbox table.
+func digestFromPayload(payload *model.NotificationPayload) DigestSummary {
+	summary := DigestSummary{
+		Likes:         payloadInt(payload.Data, "likes"),
+		Visitors:      payloadInt(payload.Data, "visitors"),
+		TotalLikes:    payloadInt(payload.Data, "total_likes"),
+		TotalVisitors: payloadInt(payload.Data, "total_visitors"),
+	}
+	if since, ok := payload.Data["since"].(string); ok {
+		summary.Since, _ = time.Parse(time.RFC3339, since)
+	}
+	return summary
+}
+
+func payloadInt(data map[string]any, key string) int {
+	switch v := data[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
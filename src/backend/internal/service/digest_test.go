@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"main/internal/config"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDigestStorage struct {
+	mock.Mock
+}
+
+func (m *MockDigestStorage) GetDigestState(ctx context.Context) (*model.DigestState, error) {
+	args := m.Called(ctx)
+	if state, ok := args.Get(0).(*model.DigestState); ok {
+		return state, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockDigestStorage) SaveDigestState(ctx context.Context, state *model.DigestState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func newTestDigest(prev *model.DigestState, likes, visitors int) (*DigestService, *MockDigestStorage, *recordingNotifier) {
+	counters := new(MockStorage)
+	counters.On("GetCount", mock.Anything, "likes").Return(likes, nil)
+	counters.On("GetCount", mock.Anything, "visitors").Return(visitors, nil)
+
+	state := new(MockDigestStorage)
+	state.On("GetDigestState", mock.Anything).Return(prev, nil)
+	state.On("SaveDigestState", mock.Anything, mock.Anything).Return(nil)
+
+	slack := &recordingNotifier{name: "slack"}
+	dispatcher := NewNotificationDispatcher()
+	dispatcher.Register(slack, []string{"digest"})
+	outbox := NewOutboxService(nil, dispatcher, nil, &config.Config{})
+
+	ds := NewDigestService(counters, state, outbox, nil, &config.Config{DigestSkipEmpty: true})
+	ds.now = func() time.Time { return time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC) }
+	return ds, state, slack
+}
+
+func TestDigestService_SendDigest(t *testing.T) {
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("sends the difference since the last digest", func(t *testing.T) {
+		ds, state, slack := newTestDigest(&model.DigestState{Likes: 10, Visitors: 100, SentAt: since}, 24, 330)
+
+		err := ds.SendDigest(context.Background())
+		assert.NoError(t, err)
+
+		if assert.Len(t, slack.payloads, 1) {
+			summary := digestFromPayload(slack.payloads[0])
+			assert.Equal(t, DigestSummary{Likes: 14, Visitors: 230, TotalLikes: 24, TotalVisitors: 330, Since: since}, summary)
+		}
+		state.AssertCalled(t, "SaveDigestState", mock.Anything, mock.MatchedBy(func(s *model.DigestState) bool {
+			return s.Likes == 24 && s.Visitors == 330
+		}))
+	})
+
+	t.Run("first run only records totals", func(t *testing.T) {
+		ds, state, slack := newTestDigest(nil, 24, 330)
+
+		err := ds.SendDigest(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, slack.payloads)
+		state.AssertNumberOfCalls(t, "SaveDigestState", 1)
+	})
+
+	t.Run("skips empty digests", func(t *testing.T) {
+		ds, _, slack := newTestDigest(&model.DigestState{Likes: 24, Visitors: 330, SentAt: since}, 24, 330)
+
+		err := ds.SendDigest(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, slack.payloads)
+	})
+}
+
+func TestDigestService_SendDigest_TurnedOff(t *testing.T) {
+	ds, state, slack := newTestDigest(&model.DigestState{Likes: 10, Visitors: 100, SentAt: time.Now()}, 24, 330)
+	ds.flags = NewFeatureFlagService(map[string]config.FeatureFlag{FlagDigest: {Rollout: 0}}, nil, time.Minute)
+
+	err := ds.SendDigest(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, slack.payloads)
+	state.AssertNotCalled(t, "SaveDigestState", mock.Anything, mock.Anything)
+}
+
+func TestDigestFromPayload(t *testing.T) {
+	// Numbers are float64 after a round trip through JSON or DynamoDB
+	payload := &model.NotificationPayload{
+		Type: "digest",
+		Data: map[string]any{"likes": float64(3), "visitors": 1, "since": "2024-01-01T12:00:00Z"},
+	}
+
+	summary := digestFromPayload(payload)
+	assert.Equal(t, 3, summary.Likes)
+	assert.Equal(t, 1, summary.Visitors)
+	assert.Equal(t, "3 new likes, 1 new visitor", summary.String())
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), summary.Since)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed data/templates
+var embeddedEmailTemplates embed.FS
+
+// EmailData is the data available to email templates
+type EmailData struct {
+	ID        string
+	Name      string
+	Email     string
+	Message   string
+	Source    string
+	SiteURL   string
+	Timestamp time.Time
+	Digest    DigestSummary
+}
+
+// RenderedEmail is an email ready to be sent as multipart text and HTML
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailTemplates renders the email for each notification type from three templates:
+// <type>.subject.txt, <type>.txt and <type>.html. HTML templates escape user-supplied fields.
+// Emails with a fixed subject, like the auto-reply, only have a .txt body template.
+type EmailTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// LoadEmailTemplates parses the embedded default templates, replacing them with any
+// template of the same name found in dir
+func LoadEmailTemplates(dir string) (*EmailTemplates, error) {
+	defaults, err := fs.Sub(embeddedEmailTemplates, "data/templates")
+	if err != nil {
+		return nil, err
+	}
+
+	et := &EmailTemplates{
+		text: texttemplate.New("email"),
+		html: htmltemplate.New("email"),
+	}
+	if err := et.parse(defaults); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := et.parse(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("failed to load email templates from %s: %w", dir, err)
+		}
+	}
+	return et, nil
+}
+
+func (et *EmailTemplates) parse(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (path.Ext(name) != ".txt" && path.Ext(name) != ".html") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		if path.Ext(name) == ".html" {
+			_, err = et.html.New(name).Parse(string(content))
+		} else {
+			_, err = et.text.New(name).Parse(string(content))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse email template %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Render renders the subject and bodies for a notification type. The HTML body is optional.
+func (et *EmailTemplates) Render(notificationType string, data EmailData) (*RenderedEmail, error) {
+	subjectTmpl := et.text.Lookup(notificationType + ".subject.txt")
+	textTmpl := et.text.Lookup(notificationType + ".txt")
+	if subjectTmpl == nil || textTmpl == nil {
+		return nil, fmt.Errorf("unknown notification type: %s", notificationType)
+	}
+
+	var subject, text strings.Builder
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s subject: %w", notificationType, err)
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s text body: %w", notificationType, err)
+	}
+
+	email := &RenderedEmail{
+		// Newlines in a subject would be rejected by SES
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    text.String(),
+	}
+
+	if htmlTmpl := et.html.Lookup(notificationType + ".html"); htmlTmpl != nil {
+		var html strings.Builder
+		if err := htmlTmpl.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s HTML body: %w", notificationType, err)
+		}
+		email.HTML = html.String()
+	}
+	return email, nil
+}
+
+// RenderText renders a single text template by file name
+func (et *EmailTemplates) RenderText(name string, data EmailData) (string, error) {
+	tmpl := et.text.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("unknown email template: %s", name)
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return text.String(), nil
+}
+
+// SampleEmailData returns placeholder data for previewing templates
+func SampleEmailData(siteURL string) EmailData {
+	return EmailData{
+		ID:        "sample-message-id",
+		Name:      "Jane Doe",
+		Email:     "jane.doe@example.com",
+		Message:   "Hi Tin,\n\nI enjoyed your resume and would love to chat about <an opportunity> & more.",
+		Source:    "resume-website",
+		SiteURL:   siteURL,
+		Timestamp: time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC),
+		Digest: DigestSummary{
+			Likes:         14,
+			Visitors:      230,
+			TotalLikes:    512,
+			TotalVisitors: 10240,
+			Since:         time.Date(2024, 1, 1, 15, 4, 0, 0, time.UTC),
+		},
+	}
+}
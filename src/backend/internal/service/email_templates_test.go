@@ -0,0 +1,80 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailTemplates_RenderDefaults(t *testing.T) {
+	templates, err := LoadEmailTemplates("")
+	assert.NoError(t, err)
+
+	data := SampleEmailData("https://example.com")
+	data.Name = `<script>alert("hi")</script>`
+
+	t.Run("contact", func(t *testing.T) {
+		email, err := templates.Render("contact", data)
+		assert.NoError(t, err)
+		assert.Equal(t, "New Contact Form Submission on Your Cloud Resume!", email.Subject)
+		assert.Contains(t, email.Text, `Name: <script>alert("hi")</script>`)
+		assert.Contains(t, email.HTML, "&lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt;")
+		assert.NotContains(t, email.HTML, "<script>")
+	})
+
+	t.Run("like", func(t *testing.T) {
+		email, err := templates.Render("like", data)
+		assert.NoError(t, err)
+		assert.Contains(t, email.Text, "Visit your resume: https://example.com")
+		assert.Contains(t, email.HTML, `href="https://example.com"`)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := templates.Render("unknown", data)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmailTemplates_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "like.subject.txt"), []byte("Custom {{.Source}}\n"), 0o644)
+	assert.NoError(t, err)
+
+	templates, err := LoadEmailTemplates(dir)
+	assert.NoError(t, err)
+
+	email, err := templates.Render("like", SampleEmailData("https://example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Custom resume-website", email.Subject)
+	assert.Contains(t, email.Text, "Someone liked your resume!")
+}
+
+func TestEmailTemplates_InvalidOverride(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "contact.html"), []byte("{{.Name"), 0o644)
+	assert.NoError(t, err)
+
+	_, err = LoadEmailTemplates(dir)
+	assert.ErrorContains(t, err, "contact.html")
+
+	_, err = LoadEmailTemplates(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestEmailTemplates_RenderText(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "auto_reply.txt"), []byte("Thanks {{.Name}}, reply from {{.SiteURL}}"), 0o644)
+	assert.NoError(t, err)
+
+	templates, err := LoadEmailTemplates(dir)
+	assert.NoError(t, err)
+
+	body, err := templates.RenderText("auto_reply.txt", EmailData{Name: "Jane", SiteURL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Thanks Jane, reply from https://example.com", body)
+
+	_, err = templates.RenderText("missing.txt", EmailData{})
+	assert.ErrorContains(t, err, "unknown email template: missing.txt")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/storage"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Feature flags, as named in FEATURE_FLAGS and the feature_flags item
+const (
+	FlagLikes     = "likes"
+	FlagContact   = "contact"
+	FlagSMS       = "sms"
+	FlagDigest    = "digest"
+	FlagBotVisits = "bot_visits" // count bot traffic separately from visitors
+)
+
+// FeatureFlagService decides which features are on. Flags come from the configuration,
+// overridden by the feature_flags item of the counters table when storage is set.
+// A nil service has every feature on.
+type FeatureFlagService struct {
+	defaults map[string]config.FeatureFlag
+	storage  storage.FeatureFlagStorageInterface
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	flags    map[string]config.FeatureFlag
+	loadedAt time.Time
+}
+
+// NewFeatureFlagService creates the feature flags, reading the overrides from storage at most
+// once per cacheTTL. Only the configured flags are used when storage is nil.
+func NewFeatureFlagService(defaults map[string]config.FeatureFlag, storage storage.FeatureFlagStorageInterface, cacheTTL time.Duration) *FeatureFlagService {
+	return &FeatureFlagService{
+		defaults: defaults,
+		storage:  storage,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether a feature is on for everyone. Features being rolled out to part of
+// the sessions are off.
+func (fs *FeatureFlagService) Enabled(ctx context.Context, name string) bool {
+	return fs.EnabledFor(ctx, name, "")
+}
+
+// EnabledFor reports whether a feature is on for a session. A rollout covers the same sessions
+// for as long as its percentage doesn't drop, and requests without a session only get features
+// that are fully on.
+func (fs *FeatureFlagService) EnabledFor(ctx context.Context, name, sessionID string) bool {
+	if fs == nil {
+		return true
+	}
+
+	flag, ok := fs.load(ctx)[name]
+	switch {
+	case !ok || flag.Rollout >= 100:
+		return true
+	case flag.Rollout <= 0 || sessionID == "":
+		return false
+	}
+	return rolloutBucket(name, sessionID) < flag.Rollout
+}
+
+// Features returns whether each named feature is on for a session, for the site to hide what's off
+func (fs *FeatureFlagService) Features(ctx context.Context, sessionID string, names ...string) map[string]bool {
+	features := make(map[string]bool, len(names))
+	for _, name := range names {
+		features[name] = fs.EnabledFor(ctx, name, sessionID)
+	}
+	return features
+}
+
+// rolloutBucket places a session in one of 100 buckets, independently for each flag so the
+// same sessions don't get every rollout first
+func rolloutBucket(name, sessionID string) int {
+	sum := sha256.Sum256([]byte(name + ":" + sessionID))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
+}
+
+// load returns the flags with the stored overrides applied, reading them again once the cache
+// has expired. The previous flags are kept while storage fails.
+func (fs *FeatureFlagService) load(ctx context.Context) map[string]config.FeatureFlag {
+	if fs.storage == nil {
+		return fs.defaults
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	now := fs.now()
+	if fs.flags != nil && now.Sub(fs.loadedAt) < fs.cacheTTL {
+		return fs.flags
+	}
+
+	// Storage isn't retried before the cache expires again
+	fs.loadedAt = now
+	if fs.flags == nil {
+		fs.flags = fs.defaults
+	}
+
+	overrides, err := fs.storage.GetFeatureFlags(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading feature flags, keeping the previous ones", "error", err)
+		return fs.flags
+	}
+
+	flags := maps.Clone(fs.defaults)
+	for name, value := range overrides {
+		if _, ok := fs.defaults[name]; !ok {
+			slog.WarnContext(ctx, "Ignoring unknown feature flag", "flag", name)
+			continue
+		}
+		flag, err := config.ParseFeatureFlag(value)
+		if err != nil {
+			slog.WarnContext(ctx, "Ignoring invalid feature flag", "flag", name, "error", err)
+			continue
+		}
+		flags[name] = flag
+	}
+	fs.flags = flags
+	return flags
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeatureFlagStorage struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlagStorage) GetFeatureFlags(ctx context.Context) (map[string]string, error) {
+	args := m.Called(ctx)
+	if flags, ok := args.Get(0).(map[string]string); ok {
+		return flags, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+var testFeatureFlags = map[string]config.FeatureFlag{
+	FlagLikes:     {Rollout: 100},
+	FlagContact:   {Rollout: 0},
+	FlagBotVisits: {Rollout: 25},
+}
+
+func TestFeatureFlagService_EnabledFor(t *testing.T) {
+	fs := NewFeatureFlagService(testFeatureFlags, nil, time.Minute)
+	ctx := context.Background()
+
+	assert.True(t, fs.Enabled(ctx, FlagLikes))
+	assert.False(t, fs.Enabled(ctx, FlagContact))
+	assert.False(t, fs.Enabled(ctx, FlagBotVisits), "partial rollouts are off without a session")
+	assert.True(t, fs.Enabled(ctx, "unconfigured"))
+	assert.False(t, fs.EnabledFor(ctx, FlagContact, "session-1"))
+
+	enabled := 0
+	for i := range 1000 {
+		sessionID := fmt.Sprintf("session-%d", i)
+		first := fs.EnabledFor(ctx, FlagBotVisits, sessionID)
+		assert.Equal(t, first, fs.EnabledFor(ctx, FlagBotVisits, sessionID), "rollout is stable per session")
+		if first {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 250, enabled, 50)
+
+	assert.Equal(t, map[string]bool{FlagLikes: true, FlagContact: false}, fs.Features(ctx, "session-1", FlagLikes, FlagContact))
+}
+
+func TestFeatureFlagService_NilHasEverythingOn(t *testing.T) {
+	var fs *FeatureFlagService
+	assert.True(t, fs.Enabled(context.Background(), FlagSMS))
+	assert.True(t, fs.EnabledFor(context.Background(), FlagBotVisits, "session-1"))
+}
+
+func TestFeatureFlagService_StoredOverrides(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	t.Run("overrides configured flags and caches them", func(t *testing.T) {
+		mockStorage := new(MockFeatureFlagStorage)
+		mockStorage.On("GetFeatureFlags", mock.Anything).
+			Return(map[string]string{FlagLikes: "off", FlagContact: "on", "unknown": "on", FlagBotVisits: "sometimes"}, nil).Once()
+		mockStorage.On("GetFeatureFlags", mock.Anything).Return(map[string]string{FlagLikes: "on"}, nil).Once()
+
+		fs := NewFeatureFlagService(testFeatureFlags, mockStorage, time.Minute)
+		fs.now = func() time.Time { return now }
+
+		assert.False(t, fs.Enabled(ctx, FlagLikes))
+		assert.True(t, fs.Enabled(ctx, FlagContact))
+		assert.Equal(t, config.FeatureFlag{Rollout: 25}, fs.load(ctx)[FlagBotVisits], "invalid overrides are ignored")
+		assert.NotContains(t, fs.load(ctx), "unknown")
+		mockStorage.AssertNumberOfCalls(t, "GetFeatureFlags", 1)
+
+		fs.now = func() time.Time { return now.Add(time.Minute) }
+		assert.True(t, fs.Enabled(ctx, FlagLikes))
+		assert.False(t, fs.Enabled(ctx, FlagContact), "removed overrides fall back to the configuration")
+		mockStorage.AssertNumberOfCalls(t, "GetFeatureFlags", 2)
+	})
+
+	t.Run("keeps the previous flags when storage fails", func(t *testing.T) {
+		mockStorage := new(MockFeatureFlagStorage)
+		mockStorage.On("GetFeatureFlags", mock.Anything).Return(map[string]string{FlagLikes: "off"}, nil).Once()
+		mockStorage.On("GetFeatureFlags", mock.Anything).Return(nil, errors.New("throttled"))
+
+		fs := NewFeatureFlagService(testFeatureFlags, mockStorage, time.Minute)
+		fs.now = func() time.Time { return now }
+		assert.False(t, fs.Enabled(ctx, FlagLikes))
+
+		fs.now = func() time.Time { return now.Add(time.Minute) }
+		assert.False(t, fs.Enabled(ctx, FlagLikes))
+		assert.False(t, fs.Enabled(ctx, FlagLikes))
+		mockStorage.AssertNumberOfCalls(t, "GetFeatureFlags", 2)
+	})
+
+	t.Run("uses the configuration when storage fails at first", func(t *testing.T) {
+		mockStorage := new(MockFeatureFlagStorage)
+		mockStorage.On("GetFeatureFlags", mock.Anything).Return(nil, errors.New("throttled"))
+
+		fs := NewFeatureFlagService(testFeatureFlags, mockStorage, time.Minute)
+		fs.now = func() time.Time { return now }
+		assert.True(t, fs.Enabled(ctx, FlagLikes))
+		assert.False(t, fs.Enabled(ctx, FlagContact))
+	})
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// hashIdentifier hashes identifiers such as IP addresses and session IDs before they are persisted
+func hashIdentifier(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	ses "github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// readinessTimeout bounds each deep readiness check
+const readinessTimeout = 3 * time.Second
+
+// SESIdentityGetter looks up the verification status of a sending identity
+type SESIdentityGetter interface {
+	GetEmailIdentity(ctx context.Context, params *ses.GetEmailIdentityInput, optFns ...func(*ses.Options)) (*ses.GetEmailIdentityOutput, error)
+}
+
+// HealthService answers the readiness checks
+type HealthService struct {
+	config  *config.Config
+	storage storage.HealthStorageInterface
+	ses     SESIdentityGetter
+}
+
+func NewHealthService(cfg *config.Config, storage storage.HealthStorageInterface, ses SESIdentityGetter) *HealthService {
+	return &HealthService{config: cfg, storage: storage, ses: ses}
+}
+
+// Ready checks that the configuration is complete. Deep checks also check that every
+// configured table is reachable and that the sender identity is verified in SES, and list
+// each check in the report.
+func (hs *HealthService) Ready(ctx context.Context, deep bool) *model.ReadinessReport {
+	ctx, span := tracing.Start(ctx, "HealthService.Ready")
+	defer span.End()
+
+	checks := []model.HealthCheck{hs.checkConfig()}
+	if deep {
+		checks = append(checks, hs.deepChecks(ctx)...)
+	}
+
+	report := &model.ReadinessReport{Status: model.ReadinessReady}
+	for _, check := range checks {
+		if !check.OK {
+			report.Status = model.ReadinessNotReady
+		}
+	}
+	if deep {
+		report.Checks = checks
+	}
+	return report
+}
+
+func (hs *HealthService) checkConfig() model.HealthCheck {
+	check := model.HealthCheck{Name: "config", OK: true}
+	if missing := hs.config.MissingRequired(); len(missing) > 0 {
+		check.OK = false
+		check.Error = "missing " + strings.Join(missing, ", ")
+	}
+	return check
+}
+
+// deepChecks runs the table and SES checks concurrently
+func (hs *HealthService) deepChecks(ctx context.Context) []model.HealthCheck {
+	type namedCheck struct {
+		name string
+		run  func(context.Context) error
+	}
+
+	var pending []namedCheck
+	for _, table := range []string{
+		hs.config.DynamoDBTable,
+		hs.config.SessionTable,
+		hs.config.InboxTable,
+		hs.config.RateLimitTable,
+		hs.config.OutboxTable,
+		hs.config.BlocklistTable,
+	} {
+		if table == "" {
+			continue
+		}
+		pending = append(pending, namedCheck{"dynamodb:" + table, func(ctx context.Context) error {
+			return hs.storage.CheckTable(ctx, table)
+		}})
+	}
+	pending = append(pending, namedCheck{"ses", hs.checkSESIdentity})
+
+	checks := make([]model.HealthCheck, len(pending))
+	var wg sync.WaitGroup
+	for i, c := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.run(ctx)
+			checks[i] = model.HealthCheck{Name: c.name, OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				checks[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+// checkSESIdentity checks that the sender address, or its domain, is verified for sending
+func (hs *HealthService) checkSESIdentity(ctx context.Context) error {
+	sender := hs.config.NotificationSrcEmail
+	if sender == "" {
+		return errors.New("no sender address configured")
+	}
+
+	identities := []string{sender}
+	if _, domain, ok := strings.Cut(sender, "@"); ok {
+		identities = append(identities, domain)
+	}
+
+	for _, identity := range identities {
+		response, err := hs.ses.GetEmailIdentity(ctx, &ses.GetEmailIdentityInput{EmailIdentity: &identity})
+		var notFound *types.NotFoundException
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get SES identity %s: %w", identity, err)
+		}
+		if !response.VerifiedForSendingStatus {
+			return fmt.Errorf("SES identity %s isn't verified for sending", identity)
+		}
+		return nil
+	}
+	return fmt.Errorf("no SES identity for %s", sender)
+}
+
+// BuildVersion describes the running binary from the build information embedded by the Go
+// toolchain. The commit is "unknown" when the binary was built outside a git checkout.
+func BuildVersion() *model.VersionInfo {
+	version := &model.VersionInfo{Commit: "unknown"}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version
+	}
+
+	version.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version.Commit = setting.Value
+		case "vcs.time":
+			version.CommitTime = setting.Value
+		case "vcs.modified":
+			version.Modified = setting.Value == "true"
+		}
+	}
+	return version
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"testing"
+
+	ses "github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthStorage struct {
+	mock.Mock
+}
+
+func (m *MockHealthStorage) CheckTable(ctx context.Context, tableName string) error {
+	args := m.Called(ctx, tableName)
+	return args.Error(0)
+}
+
+// fakeSESIdentities answers GetEmailIdentity from a map of identity to verification status
+type fakeSESIdentities map[string]bool
+
+func (f fakeSESIdentities) GetEmailIdentity(ctx context.Context, params *ses.GetEmailIdentityInput, optFns ...func(*ses.Options)) (*ses.GetEmailIdentityOutput, error) {
+	verified, ok := f[*params.EmailIdentity]
+	if !ok {
+		return nil, &types.NotFoundException{}
+	}
+	return &ses.GetEmailIdentityOutput{VerifiedForSendingStatus: verified}, nil
+}
+
+func testHealthConfig() *config.Config {
+	return &config.Config{
+		DynamoDBTable:        "counters",
+		SessionTable:         "sessions",
+		InboxTable:           "inbox",
+		CaptchaSecretKey:     "secret",
+		CSRFSecret:           "csrf-secret",
+		NotificationSrcEmail: "noreply@example.com",
+		NotificationDstEmail: "owner@example.com",
+	}
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	t.Run("shallow check only looks at config", func(t *testing.T) {
+		cfg := testHealthConfig()
+		cfg.InboxTable = ""
+		service := NewHealthService(cfg, &MockHealthStorage{}, fakeSESIdentities{})
+
+		report := service.Ready(context.Background(), false)
+		assert.Equal(t, model.ReadinessNotReady, report.Status)
+		assert.Empty(t, report.Checks)
+	})
+
+	t.Run("deep check", func(t *testing.T) {
+		storage := &MockHealthStorage{}
+		storage.On("CheckTable", mock.Anything, "counters").Return(nil)
+		storage.On("CheckTable", mock.Anything, "sessions").Return(nil)
+		storage.On("CheckTable", mock.Anything, "inbox").Return(nil)
+		service := NewHealthService(testHealthConfig(), storage, fakeSESIdentities{"example.com": true})
+
+		report := service.Ready(context.Background(), true)
+		assert.Equal(t, model.ReadinessReady, report.Status)
+		assert.Len(t, report.Checks, 5)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("deep check failures", func(t *testing.T) {
+		storage := &MockHealthStorage{}
+		storage.On("CheckTable", mock.Anything, "counters").Return(nil)
+		storage.On("CheckTable", mock.Anything, "sessions").Return(errors.New("table sessions is CREATING"))
+		storage.On("CheckTable", mock.Anything, "inbox").Return(nil)
+		service := NewHealthService(testHealthConfig(), storage, fakeSESIdentities{"noreply@example.com": false})
+
+		report := service.Ready(context.Background(), true)
+		assert.Equal(t, model.ReadinessNotReady, report.Status)
+
+		failed := map[string]string{}
+		for _, check := range report.Checks {
+			if !check.OK {
+				failed[check.Name] = check.Error
+			}
+		}
+		assert.Equal(t, map[string]string{
+			"dynamodb:sessions": "table sessions is CREATING",
+			"ses":               "SES identity noreply@example.com isn't verified for sending",
+		}, failed)
+	})
+}
+
+func TestBuildVersion(t *testing.T) {
+	version := BuildVersion()
+	assert.NotEmpty(t, version.Commit)
+	assert.NotEmpty(t, version.GoVersion)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"time"
+)
+
+const (
+	defaultInboxPageSize = 20
+	maxInboxPageSize     = 100
+)
+
+// ErrInvalidStatus is returned for statuses a contact message can't have
+var ErrInvalidStatus = errors.New("invalid message status")
+
+var validContactStatuses = map[string]bool{
+	model.ContactStatusNew:      true,
+	model.ContactStatusRead:     true,
+	model.ContactStatusArchived: true,
+	model.ContactStatusSpam:     true,
+
+	model.ContactStatusQuarantined: true,
+}
+
+type InboxService struct {
+	storage storage.InboxStorageInterface
+}
+
+func NewInboxService(storage storage.InboxStorageInterface) *InboxService {
+	return &InboxService{storage: storage}
+}
+
+// ListMessages returns a page of messages with the given status created between from and to.
+// Missing bounds default to the beginning of time and now, and the page size is clamped.
+func (is *InboxService) ListMessages(ctx context.Context, status string, from, to time.Time, limit int, cursor string) (messages []model.ContactMessage, nextCursor string, err error) {
+	ctx, span := tracing.Start(ctx, "InboxService.ListMessages")
+	defer func() { tracing.End(span, err) }()
+
+	if status == "" {
+		status = model.ContactStatusNew
+	}
+	if !validContactStatuses[status] {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidStatus, status)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if limit <= 0 {
+		limit = defaultInboxPageSize
+	}
+	limit = min(limit, maxInboxPageSize)
+
+	return is.storage.ListContactMessages(ctx, storage.MessageQuery{
+		Status: status,
+		From:   from,
+		To:     to,
+		Limit:  int32(limit),
+		Cursor: cursor,
+	})
+}
+
+// GetMessage returns a message, nil if it doesn't exist
+func (is *InboxService) GetMessage(ctx context.Context, id string) (msg *model.ContactMessage, err error) {
+	ctx, span := tracing.Start(ctx, "InboxService.GetMessage")
+	defer func() { tracing.End(span, err) }()
+
+	return is.storage.GetContactMessage(ctx, id)
+}
+
+// SetStatus marks a message as new, read, archived, spam or quarantined
+func (is *InboxService) SetStatus(ctx context.Context, id, status string) (err error) {
+	ctx, span := tracing.Start(ctx, "InboxService.SetStatus")
+	defer func() { tracing.End(span, err) }()
+
+	if !validContactStatuses[status] {
+		return fmt.Errorf("%w: %s", ErrInvalidStatus, status)
+	}
+	return is.storage.UpdateContactMessageStatus(ctx, id, status)
+}
+
+func (is *InboxService) DeleteMessage(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "InboxService.DeleteMessage")
+	defer func() { tracing.End(span, err) }()
+
+	return is.storage.DeleteContactMessage(ctx, id)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"main/internal/model"
+	"main/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInboxStorage struct {
+	mock.Mock
+}
+
+func (m *MockInboxStorage) CreateContactMessage(ctx context.Context, msg *model.ContactMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockInboxStorage) GetContactMessage(ctx context.Context, id string) (*model.ContactMessage, error) {
+	args := m.Called(ctx, id)
+	if msg, ok := args.Get(0).(*model.ContactMessage); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInboxStorage) ListContactMessages(ctx context.Context, query storage.MessageQuery) ([]model.ContactMessage, string, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]model.ContactMessage), args.String(1), args.Error(2)
+}
+
+func (m *MockInboxStorage) UpdateContactMessageStatus(ctx context.Context, id, status string) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockInboxStorage) DeleteContactMessage(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestInboxService_ListMessages(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		status        string
+		limit         int
+		mockSetup     func(*MockInboxStorage)
+		expectedError bool
+	}{
+		{
+			name:   "defaults to new messages",
+			status: "",
+			limit:  0,
+			mockSetup: func(m *MockInboxStorage) {
+				m.On("ListContactMessages", mock.Anything, storage.MessageQuery{
+					Status: model.ContactStatusNew, From: from, To: to, Limit: defaultInboxPageSize,
+				}).Return([]model.ContactMessage{{ID: "msg-1"}}, "", nil)
+			},
+		},
+		{
+			name:   "page size is clamped",
+			status: model.ContactStatusSpam,
+			limit:  1000,
+			mockSetup: func(m *MockInboxStorage) {
+				m.On("ListContactMessages", mock.Anything, storage.MessageQuery{
+					Status: model.ContactStatusSpam, From: from, To: to, Limit: maxInboxPageSize,
+				}).Return([]model.ContactMessage{}, "", nil)
+			},
+		},
+		{
+			name:          "invalid status",
+			status:        "deleted",
+			mockSetup:     func(m *MockInboxStorage) {},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockInboxStorage{}
+			tt.mockSetup(mockStorage)
+
+			service := NewInboxService(mockStorage)
+			_, _, err := service.ListMessages(context.Background(), tt.status, from, to, tt.limit, "")
+
+			if tt.expectedError {
+				assert.ErrorIs(t, err, ErrInvalidStatus)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInboxService_SetStatus(t *testing.T) {
+	mockStorage := &MockInboxStorage{}
+	mockStorage.On("UpdateContactMessageStatus", mock.Anything, "msg-1", model.ContactStatusArchived).Return(nil)
+
+	service := NewInboxService(mockStorage)
+	assert.NoError(t, service.SetStatus(context.Background(), "msg-1", model.ContactStatusArchived))
+	assert.ErrorIs(t, service.SetStatus(context.Background(), "msg-1", "unknown"), ErrInvalidStatus)
+
+	mockStorage.AssertExpectations(t)
+}
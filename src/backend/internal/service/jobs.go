@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/tracing"
+	"slices"
+	"time"
+)
+
+// Background job names, as used in scheduled events and JOB_<NAME>_* settings
+const (
+	JobDigest         = "digest"
+	JobCounterRollup  = "counter_rollup"
+	JobSessionCleanup = "session_cleanup"
+	JobOutboxRetry    = "outbox_retry"
+)
+
+// ErrUnknownJob is returned when running a job that isn't registered
+var ErrUnknownJob = errors.New("unknown job")
+
+// JobFunc runs one invocation of a background job
+type JobFunc func(ctx context.Context, cfg config.JobConfig) error
+
+// JobRegistry runs background jobs by name with their configured timeout
+type JobRegistry struct {
+	jobs    map[string]JobFunc
+	configs map[string]config.JobConfig
+}
+
+func NewJobRegistry(configs map[string]config.JobConfig) *JobRegistry {
+	return &JobRegistry{
+		jobs:    make(map[string]JobFunc),
+		configs: configs,
+	}
+}
+
+func (r *JobRegistry) Register(name string, job JobFunc) {
+	r.jobs[name] = job
+}
+
+// Names returns the registered jobs in alphabetical order
+func (r *JobRegistry) Names() []string {
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Config returns the configuration a job runs with. Jobs without configuration are enabled
+// and have no timeout.
+func (r *JobRegistry) Config(name string) config.JobConfig {
+	if cfg, ok := r.configs[name]; ok {
+		return cfg
+	}
+	return config.JobConfig{Enabled: true}
+}
+
+// Run runs a job, skipping it if it is disabled
+func (r *JobRegistry) Run(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "JobRegistry.Run")
+	defer func() { tracing.End(span, err) }()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownJob, name)
+	}
+
+	cfg := r.Config(name)
+	if !cfg.Enabled {
+		slog.InfoContext(ctx, "Job is disabled, skipping", "job", name)
+		return nil
+	}
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := job(ctx, cfg); err != nil {
+		return fmt.Errorf("job %s failed after %s: %w", name, time.Since(start), err)
+	}
+	slog.InfoContext(ctx, "Job finished", "job", name, "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRegistry_Run(t *testing.T) {
+	registry := NewJobRegistry(map[string]config.JobConfig{
+		"enabled":  {Enabled: true, Timeout: time.Minute, BatchSize: 10},
+		"disabled": {Enabled: false},
+		"failing":  {Enabled: true},
+		"slow":     {Enabled: true, Timeout: 10 * time.Millisecond},
+	})
+
+	var ran []string
+	var gotCfg config.JobConfig
+	registry.Register("enabled", func(ctx context.Context, cfg config.JobConfig) error {
+		ran = append(ran, "enabled")
+		gotCfg = cfg
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		return nil
+	})
+	registry.Register("disabled", func(ctx context.Context, cfg config.JobConfig) error {
+		ran = append(ran, "disabled")
+		return nil
+	})
+	registry.Register("failing", func(ctx context.Context, cfg config.JobConfig) error {
+		return errors.New("boom")
+	})
+	registry.Register("slow", func(ctx context.Context, cfg config.JobConfig) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	registry.Register("unconfigured", func(ctx context.Context, cfg config.JobConfig) error {
+		ran = append(ran, "unconfigured")
+		return nil
+	})
+
+	assert.Equal(t, []string{"disabled", "enabled", "failing", "slow", "unconfigured"}, registry.Names())
+
+	assert.NoError(t, registry.Run(context.Background(), "enabled"))
+	assert.Equal(t, 10, gotCfg.BatchSize)
+	assert.NoError(t, registry.Run(context.Background(), "disabled"))
+	assert.NoError(t, registry.Run(context.Background(), "unconfigured"))
+	assert.Equal(t, []string{"enabled", "unconfigured"}, ran)
+
+	assert.ErrorContains(t, registry.Run(context.Background(), "failing"), "boom")
+	assert.ErrorIs(t, registry.Run(context.Background(), "slow"), context.DeadlineExceeded)
+	assert.ErrorIs(t, registry.Run(context.Background(), "missing"), ErrUnknownJob)
+}
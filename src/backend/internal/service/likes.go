@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"main/internal/metrics"
 	"main/internal/model"
 	"main/internal/storage"
+	"main/internal/tracing"
 )
 
 type LikeService struct {
@@ -14,15 +16,17 @@ func NewLikeService(storage storage.StorageInterface) *LikeService {
 	return &LikeService{storage: storage}
 }
 
-func (ls *LikeService) GetLikeCount(ctx context.Context) (int, error) {
+func (ls *LikeService) GetLikeCount(ctx context.Context) (count int, err error) {
+	ctx, span := tracing.Start(ctx, "LikeService.GetLikeCount")
+	defer func() { tracing.End(span, err) }()
+
 	return ls.storage.GetCount(ctx, "likes")
 }
 
 // ToggleLike toggles the like status for a session and returns the updated count and action taken
-func (ls *LikeService) ToggleLike(ctx context.Context, session *model.UserSession) (int, string, error) {
-	var count int
-	var action string
-	var err error
+func (ls *LikeService) ToggleLike(ctx context.Context, session *model.UserSession) (count int, action string, err error) {
+	ctx, span := tracing.Start(ctx, "LikeService.ToggleLike")
+	defer func() { tracing.End(span, err) }()
 
 	if session.HasLiked {
 		count, err = ls.storage.DecrementCount(ctx, "likes")
@@ -38,5 +42,10 @@ func (ls *LikeService) ToggleLike(ctx context.Context, session *model.UserSessio
 		return 0, "", err
 	}
 
+	if action == "liked" {
+		metrics.Emit(nil, metrics.Count("Likes", 1))
+	} else {
+		metrics.Emit(nil, metrics.Count("Unlikes", 1))
+	}
 	return count, action, nil
 }
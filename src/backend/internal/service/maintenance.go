@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"time"
+)
+
+// SessionCleanupStorage deletes expired sessions created before sessions had a TTL attribute,
+// which the session table's TTL can't expire
+type SessionCleanupStorage interface {
+	DeleteExpiredSessions(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// MaintenanceService implements the counter rollup and session cleanup jobs
+type MaintenanceService struct {
+	counters storage.StorageInterface
+	rollups  storage.RollupStorageInterface
+	sessions SessionCleanupStorage
+	location *time.Location
+	now      func() time.Time
+}
+
+func NewMaintenanceService(counters storage.StorageInterface, rollups storage.RollupStorageInterface, sessions SessionCleanupStorage, cfg *config.Config) *MaintenanceService {
+	return &MaintenanceService{
+		counters: counters,
+		rollups:  rollups,
+		sessions: sessions,
+		location: loadLocation(cfg.NotificationTimezone),
+		now:      time.Now,
+	}
+}
+
+// RollupCounters saves today's counter totals, dated in the notification timezone
+func (ms *MaintenanceService) RollupCounters(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "MaintenanceService.RollupCounters")
+	defer func() { tracing.End(span, err) }()
+
+	likes, err := ms.counters.GetCount(ctx, "likes")
+	if err != nil {
+		return fmt.Errorf("failed to get like count: %w", err)
+	}
+	visitors, err := ms.counters.GetCount(ctx, "visitors")
+	if err != nil {
+		return fmt.Errorf("failed to get visitor count: %w", err)
+	}
+	// The bot counter only exists once a bot was seen
+	botVisits, err := ms.counters.GetCount(ctx, "bot_visits")
+	if errors.Is(err, storage.ErrNotFound) {
+		botVisits = 0
+	} else if err != nil {
+		return fmt.Errorf("failed to get bot visit count: %w", err)
+	}
+
+	now := ms.now()
+	return ms.rollups.SaveCounterSnapshot(ctx, &model.CounterSnapshot{
+		Date:      now.In(ms.location).Format(time.DateOnly),
+		Likes:     likes,
+		Visitors:  visitors,
+		BotVisits: botVisits,
+		CreatedAt: now,
+	})
+}
+
+// CleanupSessions deletes up to limit expired sessions created before sessions had a TTL
+// attribute. It is disabled by default, run it once after deploying the TTL attribute.
+func (ms *MaintenanceService) CleanupSessions(ctx context.Context, limit int) (err error) {
+	ctx, span := tracing.Start(ctx, "MaintenanceService.CleanupSessions")
+	defer func() { tracing.End(span, err) }()
+
+	deleted, err := ms.sessions.DeleteExpiredSessions(ctx, ms.now(), limit)
+	slog.InfoContext(ctx, "Deleted expired sessions", "count", deleted)
+	return err
+}
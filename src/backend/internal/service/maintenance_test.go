@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRollupStorage struct {
+	mock.Mock
+}
+
+func (m *MockRollupStorage) SaveCounterSnapshot(ctx context.Context, snapshot *model.CounterSnapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
+
+type MockSessionCleanupStorage struct {
+	mock.Mock
+}
+
+func (m *MockSessionCleanupStorage) DeleteExpiredSessions(ctx context.Context, now time.Time, limit int) (int, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestMaintenanceService_RollupCounters(t *testing.T) {
+	counters := new(MockStorage)
+	counters.On("GetCount", mock.Anything, "likes").Return(12, nil)
+	counters.On("GetCount", mock.Anything, "visitors").Return(340, nil)
+	counters.On("GetCount", mock.Anything, "bot_visits").Return(0, fmt.Errorf("%w: no counter with ID bot_visits", storage.ErrNotFound))
+
+	rollups := new(MockRollupStorage)
+	rollups.On("SaveCounterSnapshot", mock.Anything, mock.Anything).Return(nil)
+
+	ms := NewMaintenanceService(counters, rollups, nil, &config.Config{NotificationTimezone: "America/New_York"})
+	// Still the previous day in New York
+	ms.now = func() time.Time { return time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC) }
+
+	err := ms.RollupCounters(context.Background())
+	assert.NoError(t, err)
+	rollups.AssertCalled(t, "SaveCounterSnapshot", mock.Anything, mock.MatchedBy(func(s *model.CounterSnapshot) bool {
+		return s.Date == "2024-01-01" && s.Likes == 12 && s.Visitors == 340 && s.BotVisits == 0
+	}))
+}
+
+func TestMaintenanceService_RollupCounters_StorageError(t *testing.T) {
+	counters := new(MockStorage)
+	counters.On("GetCount", mock.Anything, "likes").Return(12, nil)
+	counters.On("GetCount", mock.Anything, "visitors").Return(340, nil)
+	counters.On("GetCount", mock.Anything, "bot_visits").Return(0, errors.New("throttled"))
+
+	rollups := new(MockRollupStorage)
+	ms := NewMaintenanceService(counters, rollups, nil, &config.Config{NotificationTimezone: "America/New_York"})
+
+	err := ms.RollupCounters(context.Background())
+	assert.ErrorContains(t, err, "throttled")
+	rollups.AssertNotCalled(t, "SaveCounterSnapshot", mock.Anything, mock.Anything)
+}
+
+func TestMaintenanceService_CleanupSessions(t *testing.T) {
+	sessions := new(MockSessionCleanupStorage)
+	sessions.On("DeleteExpiredSessions", mock.Anything, mock.Anything, 500).Return(42, nil)
+
+	ms := NewMaintenanceService(nil, nil, sessions, &config.Config{})
+	err := ms.CleanupSessions(context.Background(), 500)
+
+	assert.NoError(t, err)
+	sessions.AssertExpectations(t)
+}
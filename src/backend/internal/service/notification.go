@@ -2,97 +2,414 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"main/internal/config"
 	"main/internal/model"
+	"main/internal/tracing"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf16"
 
 	ses "github.com/aws/aws-sdk-go-v2/service/sesv2"
 	types "github.com/aws/aws-sdk-go-v2/service/sesv2/types"
 
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
 	"github.com/aws/aws-sdk-go/aws"
 )
 
+// maxAutoReplyQuote caps how much of the sender's message is quoted back to them
+const maxAutoReplyQuote = 1000
+
+// autoReplyTemplate is the email template of the auto-reply body. It takes the sender's
+// Name, their Message and the SiteURL.
+const autoReplyTemplate = "auto_reply.txt"
+
+// SMS length limits for a single message: 160 GSM-7 septets, or 70 UTF-16 code units when
+// the message needs UCS-2 encoding
+const (
+	maxSMSLengthGSM  = 160
+	maxSMSLengthUCS2 = 70
+)
+
+// The GSM-7 default alphabet. Characters of the extension table take two septets, an escape
+// and the character.
+const (
+	gsm7Basic    = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extended = "\f^{}\\[~]|€"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// NotificationDedupLimiter is the rule name limiting notifications of the same type per session
+const NotificationDedupLimiter = "notification_dedup"
+
+// SNSPublisher is the part of the SNS client used to send SMS, so tests can use a fake
+type SNSPublisher interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SESSender is the part of the SES client used to send email, so tests can use a fake
+type SESSender interface {
+	SendEmail(ctx context.Context, params *ses.SendEmailInput, optFns ...func(*ses.Options)) (*ses.SendEmailOutput, error)
+}
+
 type NotificationService struct {
-	sesClient *ses.Client
-	snsClient *sns.Client
-	config    *config.Config
+	sesClient      SESSender
+	snsClient      SNSPublisher
+	limiter        *RateLimitService
+	flags          *FeatureFlagService
+	config         *config.Config
+	location       *time.Location
+	emailTemplates *EmailTemplates
 }
 
-func NewNotificationService(sesClient *ses.Client, snsClient *sns.Client, config *config.Config) *NotificationService {
+// NewNotificationService creates the notification service. Duplicate notifications aren't
+// suppressed when limiter is nil, and every feature is on when flags is nil.
+func NewNotificationService(sesClient SESSender, snsClient SNSPublisher, limiter *RateLimitService, flags *FeatureFlagService, config *config.Config) *NotificationService {
 	return &NotificationService{
-		sesClient: sesClient,
-		snsClient: snsClient,
-		config:    config,
+		sesClient:      sesClient,
+		snsClient:      snsClient,
+		limiter:        limiter,
+		flags:          flags,
+		config:         config,
+		location:       loadLocation(config.NotificationTimezone),
+		emailTemplates: loadEmailTemplates(config.EmailTemplateDir),
+	}
+}
+
+// loadLocation loads the timezone notifications are shown in, falling back to UTC
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		slog.Warn("Couldn't load timezone, using UTC", "timezone", name, "error", err)
+		return time.UTC
+	}
+	return location
+}
+
+// loadEmailTemplates loads the templates with overrides from dir, falling back to the embedded defaults
+func loadEmailTemplates(dir string) *EmailTemplates {
+	templates, err := LoadEmailTemplates(dir)
+	if err == nil {
+		return templates
+	}
+	slog.Warn("Couldn't load email templates, using defaults", "error", err)
+
+	templates, err = LoadEmailTemplates("")
+	if err != nil {
+		panic(err)
 	}
+	return templates
 }
 
-func (ns *NotificationService) SendEmailNotification(ctx context.Context, payload *model.NotificationPayload) error {
+func (ns *NotificationService) SendEmailNotification(ctx context.Context, payload *model.NotificationPayload) (err error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.SendEmailNotification")
+	defer func() { tracing.End(span, err) }()
+
 	if ns.config.NotificationDstEmail == "" || ns.config.NotificationSrcEmail == "" {
 		return nil // No emails configured, skip sending
 	}
 
-	// check payload type and construct email content
-	estLocation, err := time.LoadLocation("America/New_York")
+	// construct email content from the payload type's templates
+	data := EmailData{
+		Source:    payload.Source,
+		SiteURL:   ns.config.SiteURL,
+		Timestamp: payload.Timestamp.In(ns.location),
+	}
+	data.ID, _ = payload.Data["id"].(string)
+	data.Name, _ = payload.Data["name"].(string)
+	data.Email, _ = payload.Data["email"].(string)
+	data.Message, _ = payload.Data["message"].(string)
+	if payload.Type == "digest" {
+		data.Digest = digestFromPayload(payload)
+		data.Digest.Since = data.Digest.Since.In(ns.location)
+	}
+
+	email, err := ns.emailTemplates.Render(payload.Type, data)
 	if err != nil {
-		payload.Timestamp = payload.Timestamp.In(estLocation) // Convert to EST
+		return err
+	}
+
+	body := &types.Body{
+		Text: &types.Content{
+			Charset: aws.String("UTF-8"),
+			Data:    aws.String(email.Text),
+		},
+	}
+	if email.HTML != "" {
+		body.Html = &types.Content{
+			Charset: aws.String("UTF-8"),
+			Data:    aws.String(email.HTML),
+		}
+	}
+
+	input := &ses.SendEmailInput{
+		Destination: &types.Destination{
+			CcAddresses: []string{},
+			ToAddresses: []string{
+				ns.config.NotificationDstEmail,
+			},
+		},
+
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(email.Subject),
+				},
+				Body: body,
+			},
+		},
+
+		FromEmailAddress: &ns.config.NotificationSrcEmail,
+	}
+
+	_, err = ns.sesClient.SendEmail(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to send SES email: %w", err)
+	}
+
+	return nil
+}
+
+// IsDuplicate reports whether a notification of the same type was already sent for the session
+// within the dedup window. Errors are logged and the notification is let through.
+func (ns *NotificationService) IsDuplicate(ctx context.Context, notificationType, sessionID string) bool {
+	if ns.limiter == nil || sessionID == "" {
+		return false
+	}
+	allowed, _, err := ns.limiter.AllowKey(ctx, NotificationDedupLimiter, notificationType+":"+sessionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error checking notification dedup", "error", err)
+		return false
+	}
+	return !allowed
+}
+
+// DeliveryTime returns when a notification created at t should be delivered: t itself,
+// or the end of the quiet hours if t falls within them
+func (ns *NotificationService) DeliveryTime(t time.Time) time.Time {
+	quiet := ns.config.QuietHours
+	if !quiet.Enabled() {
+		return t
+	}
+
+	local := t.In(ns.location)
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	// The end is a wall clock time, adding it to midnight would be an hour off on the days
+	// daylight saving time starts or ends
+	endOn := func(day time.Time) time.Time {
+		end := quiet.End
+		return time.Date(day.Year(), day.Month(), day.Day(), int(end/time.Hour), int(end%time.Hour/time.Minute),
+			int(end%time.Minute/time.Second), 0, ns.location).In(t.Location())
 	}
-	var subject, body string
-	switch payload.Type {
-	case "like":
-		subject = "New Like 👍 on Your Resume!"
-		body = fmt.Sprintf(`
-Someone liked your resume!
 
-Time: %s
-Source: %s
+	if quiet.Start < quiet.End {
+		if clock >= quiet.Start && clock < quiet.End {
+			return endOn(local)
+		}
+		return t
+	}
 
-Visit your resume: https://www.pwnph0fun.com
+	// Quiet hours span midnight, e.g. 22:00-07:00
+	if clock >= quiet.Start {
+		return endOn(local.AddDate(0, 0, 1))
+	}
+	if clock < quiet.End {
+		return endOn(local)
+	}
+	return t
+}
+
+// DigestLikes reports whether likes are summarized in the digest instead of notified one by one.
+// Likes are notified one by one while the digest is turned off.
+func (ns *NotificationService) DigestLikes(ctx context.Context) bool {
+	return ns.config.LikeNotificationMode == LikeNotificationsDigest && ns.flags.Enabled(ctx, FlagDigest)
+}
+
+// SMSEnabled reports whether SMS notifications are sent for the notification type
+func (ns *NotificationService) SMSEnabled(ctx context.Context, notificationType string) bool {
+	return ns.snsClient != nil && ns.config.NotificationPhoneNumber != "" &&
+		slices.Contains(ns.config.SMSNotificationTypes, notificationType) && ns.flags.Enabled(ctx, FlagSMS)
+}
 
-Very nice,
-Your Cloud Resume Lambda
-`, payload.Timestamp.Format("January 2, 2006 at 3:04 PM EST"), payload.Source)
+// SendSMSNotification texts a short summary of the notification to the configured phone number
+func (ns *NotificationService) SendSMSNotification(ctx context.Context, payload *model.NotificationPayload) (err error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.SendSMSNotification")
+	defer func() { tracing.End(span, err) }()
 
+	if !ns.SMSEnabled(ctx, payload.Type) {
+		return nil
+	}
+
+	phoneNumber, err := normalizePhoneNumber(ns.config.NotificationPhoneNumber, ns.config.SMSDefaultCountryCode)
+	if err != nil {
+		return err
+	}
+
+	var message string
+	switch payload.Type {
+	case "like":
+		message = fmt.Sprintf("New like on your resume (%s)", payload.Source)
+	case "digest":
+		message = "Resume digest: " + digestFromPayload(payload).String()
 	case "contact":
-		subject = "New Contact Form Submission on Your Cloud Resume!"
 		name, _ := payload.Data["name"].(string)
 		email, _ := payload.Data["email"].(string)
-		message, _ := payload.Data["message"].(string)
+		text, _ := payload.Data["message"].(string)
+		message = fmt.Sprintf("New contact from %s <%s>: %s", name, email, strings.Join(strings.Fields(text), " "))
+	default:
+		return fmt.Errorf("unknown notification type: %s", payload.Type)
+	}
 
-		body = fmt.Sprintf(`
-New contact form submission received:
+	attributes := map[string]snstypes.MessageAttributeValue{
+		"AWS.SNS.SMS.SMSType": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String("Transactional"),
+		},
+	}
+	if ns.config.SMSSenderID != "" {
+		attributes["AWS.SNS.SMS.SenderID"] = snstypes.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(ns.config.SMSSenderID),
+		}
+	}
 
-Name: %s
-Email: %s
-Message: %s
+	_, err = ns.snsClient.Publish(ctx, &sns.PublishInput{
+		PhoneNumber:       aws.String(phoneNumber),
+		Message:           aws.String(truncateSMS(message)),
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send SNS SMS: %w", err)
+	}
+	return nil
+}
 
-Received at: %s
-Source: %s
+// normalizePhoneNumber converts a phone number to E.164. Numbers without a country code
+// get defaultCountryCode, e.g. "(513) 555-0100" becomes "+15135550100" for country code 1.
+func normalizePhoneNumber(number, defaultCountryCode string) (string, error) {
+	number = strings.TrimSpace(number)
+	international := strings.HasPrefix(number, "+")
 
-Reply to this person directly at: %s
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
 
-Very nice,
-Your Cloud Resume Lambda
-`, name, email, message, payload.Timestamp.Format("January 2, 2006 at 3:04 PM EST"), payload.Source, email)
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case len(digits) <= 10:
+		digits = defaultCountryCode + digits
+	}
 
-	default:
-		return fmt.Errorf("unknown notification type: %s", payload.Type)
+	e164 := "+" + digits
+	if !e164Pattern.MatchString(e164) {
+		return "", errors.New("invalid notification phone number")
+	}
+	return e164, nil
+}
+
+// gsm7Septets returns how many septets r takes in GSM-7, 0 if it can't be encoded
+func gsm7Septets(r rune) int {
+	switch {
+	case strings.ContainsRune(gsm7Basic, r):
+		return 1
+	case strings.ContainsRune(gsm7Extended, r):
+		return 2
+	}
+	return 0
+}
+
+// ucs2Units returns how many UTF-16 code units r takes
+func ucs2Units(r rune) int {
+	return max(utf16.RuneLen(r), 1)
+}
+
+// truncateSMS shortens a message to fit in a single SMS. Messages with a character outside
+// GSM-7 are sent as UCS-2, which fits fewer characters.
+func truncateSMS(message string) string {
+	width, limit := gsm7Septets, maxSMSLengthGSM
+	for _, r := range message {
+		if gsm7Septets(r) == 0 {
+			width, limit = ucs2Units, maxSMSLengthUCS2
+			break
+		}
+	}
+
+	length := 0
+	for _, r := range message {
+		length += width(r)
+	}
+	if length <= limit {
+		return message
+	}
+
+	// "..." takes three units in either encoding
+	used := 0
+	for i, r := range message {
+		if used+width(r) > limit-3 {
+			return message[:i] + "..."
+		}
+		used += width(r)
+	}
+	return message
+}
+
+// AutoReplyEnabled reports whether contact form senders get an acknowledgment email
+func (ns *NotificationService) AutoReplyEnabled() bool {
+	return ns.config.AutoReplyEnabled && ns.config.NotificationSrcEmail != ""
+}
+
+// renderAutoReply fills the auto-reply template with the sender's name and a truncated copy of their message
+func (ns *NotificationService) renderAutoReply(msg *model.ContactMessage) (string, error) {
+	quote := msg.Message
+	if runes := []rune(quote); len(runes) > maxAutoReplyQuote {
+		quote = string(runes[:maxAutoReplyQuote]) + "..."
+	}
+
+	return ns.emailTemplates.RenderText(autoReplyTemplate, EmailData{
+		Name:    msg.Name,
+		Message: quote,
+		SiteURL: ns.config.SiteURL,
+	})
+}
+
+// SendAutoReply emails the sender of a contact message to confirm it was received.
+// It must only be called for messages that passed CAPTCHA verification. The subject is
+// fixed by config so that the form can't be used to send arbitrary emails.
+func (ns *NotificationService) SendAutoReply(ctx context.Context, msg *model.ContactMessage) (err error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.SendAutoReply")
+	defer func() { tracing.End(span, err) }()
+
+	if !ns.AutoReplyEnabled() {
+		return nil
+	}
+
+	body, err := ns.renderAutoReply(msg)
+	if err != nil {
+		return fmt.Errorf("failed to render auto-reply: %w", err)
 	}
 
 	input := &ses.SendEmailInput{
 		Destination: &types.Destination{
-			CcAddresses: []string{},
-			ToAddresses: []string{
-				ns.config.NotificationDstEmail,
-			},
+			ToAddresses: []string{msg.Email},
 		},
-
 		Content: &types.EmailContent{
 			Simple: &types.Message{
 				Subject: &types.Content{
 					Charset: aws.String("UTF-8"),
-					Data:    aws.String(subject),
+					Data:    aws.String(ns.config.AutoReplySubject),
 				},
 				Body: &types.Body{
 					Text: &types.Content{
@@ -102,18 +419,15 @@ Your Cloud Resume Lambda
 				},
 			},
 		},
-
 		FromEmailAddress: &ns.config.NotificationSrcEmail,
 	}
+	if ns.config.NotificationDstEmail != "" {
+		input.ReplyToAddresses = []string{ns.config.NotificationDstEmail}
+	}
 
 	_, err = ns.sesClient.SendEmail(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to send SES email: %w", err)
+		return fmt.Errorf("failed to send auto-reply: %w", err)
 	}
-
-	return nil
-}
-
-func (ns *NotificationService) SendSMSNotification(ctx context.Context, payload *model.NotificationPayload) error {
 	return nil
 }
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"strings"
+	"testing"
+	"time"
+
+	ses "github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotificationService_RenderAutoReply(t *testing.T) {
+	ns := NewNotificationService(nil, nil, nil, nil, &config.Config{SiteURL: "https://example.com"})
+
+	t.Run("quotes the message", func(t *testing.T) {
+		body, err := ns.renderAutoReply(&model.ContactMessage{Name: "Jane", Message: "Are you available for an interview?"})
+		assert.NoError(t, err)
+		assert.Contains(t, body, "Hi Jane,")
+		assert.Contains(t, body, "Are you available for an interview?")
+		assert.True(t, strings.HasSuffix(body, "https://example.com\n"))
+	})
+
+	t.Run("truncates long messages", func(t *testing.T) {
+		body, err := ns.renderAutoReply(&model.ContactMessage{Name: "Jane", Message: strings.Repeat("a", 5000)})
+		assert.NoError(t, err)
+		assert.Contains(t, body, strings.Repeat("a", maxAutoReplyQuote)+"...")
+		assert.NotContains(t, body, strings.Repeat("a", maxAutoReplyQuote+1))
+	})
+}
+
+func TestNotificationService_AutoReplyEnabled(t *testing.T) {
+	assert.False(t, NewNotificationService(nil, nil, nil, nil, &config.Config{AutoReplyEnabled: true}).AutoReplyEnabled())
+	assert.False(t, NewNotificationService(nil, nil, nil, nil, &config.Config{NotificationSrcEmail: "noreply@example.com"}).AutoReplyEnabled())
+	assert.True(t, NewNotificationService(nil, nil, nil, nil, &config.Config{AutoReplyEnabled: true, NotificationSrcEmail: "noreply@example.com"}).AutoReplyEnabled())
+}
+
+type fakeSNSPublisher struct {
+	inputs []*sns.PublishInput
+	err    error
+}
+
+func (f *fakeSNSPublisher) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.inputs = append(f.inputs, params)
+	return &sns.PublishOutput{}, f.err
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		number  string
+		want    string
+		wantErr bool
+	}{
+		{"national number", "5135550100", "+15135550100", false},
+		{"formatted national number", "(513) 555-0100", "+15135550100", false},
+		{"with country code", "15135550100", "+15135550100", false},
+		{"E.164", "+44 20 7946 0958", "+442079460958", false},
+		{"international prefix", "0044 20 7946 0958", "+442079460958", false},
+		{"too short", "55-01", "", true},
+		{"too long", "+1234567890123456", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePhoneNumber(tt.number, "1")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTruncateSMS(t *testing.T) {
+	assert.Equal(t, "short", truncateSMS("short"))
+
+	ascii := truncateSMS(strings.Repeat("a", 200))
+	assert.Len(t, []rune(ascii), maxSMSLengthGSM)
+	assert.True(t, strings.HasSuffix(ascii, "..."))
+
+	// é is in the GSM-7 alphabet
+	accented := truncateSMS(strings.Repeat("é", 200))
+	assert.Len(t, []rune(accented), maxSMSLengthGSM)
+
+	// Extension table characters take two septets
+	braces := truncateSMS(strings.Repeat("{", 100))
+	assert.Equal(t, strings.Repeat("{", 78)+"...", braces)
+	assert.Equal(t, strings.Repeat("[", 80), truncateSMS(strings.Repeat("[", 80)))
+
+	// A backtick isn't in GSM-7, so the message is sent as UCS-2
+	backtick := truncateSMS("`" + strings.Repeat("a", 100))
+	assert.Len(t, []rune(backtick), maxSMSLengthUCS2)
+
+	// Characters outside the BMP take two UTF-16 code units
+	emoji := truncateSMS(strings.Repeat("😀", 50))
+	assert.Equal(t, strings.Repeat("😀", 33)+"...", emoji)
+}
+
+func TestNotificationService_SendSMSNotification(t *testing.T) {
+	cfg := &config.Config{
+		NotificationPhoneNumber: "513-555-0100",
+		SMSNotificationTypes:    []string{"contact"},
+		SMSDefaultCountryCode:   "1",
+		SMSSenderID:             "Resume",
+	}
+	contact := &model.NotificationPayload{
+		Type:   "contact",
+		Source: "resume-website",
+		Data:   map[string]any{"name": "Jane", "email": "jane@example.com", "message": "Hello\nthere"},
+	}
+
+	t.Run("publishes a transactional SMS", func(t *testing.T) {
+		publisher := &fakeSNSPublisher{}
+		ns := NewNotificationService(nil, publisher, nil, nil, cfg)
+
+		err := ns.SendSMSNotification(context.Background(), contact)
+		assert.NoError(t, err)
+		if assert.Len(t, publisher.inputs, 1) {
+			input := publisher.inputs[0]
+			assert.Equal(t, "+15135550100", *input.PhoneNumber)
+			assert.Equal(t, "New contact from Jane <jane@example.com>: Hello there", *input.Message)
+			assert.Equal(t, "Transactional", *input.MessageAttributes["AWS.SNS.SMS.SMSType"].StringValue)
+			assert.Equal(t, "Resume", *input.MessageAttributes["AWS.SNS.SMS.SenderID"].StringValue)
+		}
+	})
+
+	t.Run("skips types that are not enabled", func(t *testing.T) {
+		publisher := &fakeSNSPublisher{}
+		ns := NewNotificationService(nil, publisher, nil, nil, cfg)
+
+		err := ns.SendSMSNotification(context.Background(), &model.NotificationPayload{Type: "like"})
+		assert.NoError(t, err)
+		assert.Empty(t, publisher.inputs)
+	})
+
+	t.Run("skips SMS while the feature is off", func(t *testing.T) {
+		publisher := &fakeSNSPublisher{}
+		flags := NewFeatureFlagService(map[string]config.FeatureFlag{FlagSMS: {Rollout: 0}}, nil, time.Minute)
+		ns := NewNotificationService(nil, publisher, nil, flags, cfg)
+
+		err := ns.SendSMSNotification(context.Background(), contact)
+		assert.NoError(t, err)
+		assert.Empty(t, publisher.inputs)
+	})
+
+	t.Run("returns publish errors", func(t *testing.T) {
+		publisher := &fakeSNSPublisher{err: errors.New("throttled")}
+		ns := NewNotificationService(nil, publisher, nil, nil, cfg)
+
+		err := ns.SendSMSNotification(context.Background(), contact)
+		assert.ErrorContains(t, err, "throttled")
+	})
+
+	t.Run("rejects an invalid phone number", func(t *testing.T) {
+		publisher := &fakeSNSPublisher{}
+		invalid := *cfg
+		invalid.NotificationPhoneNumber = "12345"
+		ns := NewNotificationService(nil, publisher, nil, nil, &invalid)
+
+		err := ns.SendSMSNotification(context.Background(), contact)
+		assert.Error(t, err)
+		assert.Empty(t, publisher.inputs)
+	})
+}
+
+type fakeSESSender struct {
+	inputs []*ses.SendEmailInput
+}
+
+func (f *fakeSESSender) SendEmail(ctx context.Context, params *ses.SendEmailInput, optFns ...func(*ses.Options)) (*ses.SendEmailOutput, error) {
+	f.inputs = append(f.inputs, params)
+	return &ses.SendEmailOutput{}, nil
+}
+
+func TestNotificationService_SendEmailNotificationTimezone(t *testing.T) {
+	sender := &fakeSESSender{}
+	ns := NewNotificationService(sender, nil, nil, nil, &config.Config{
+		NotificationDstEmail: "me@example.com",
+		NotificationSrcEmail: "noreply@example.com",
+		NotificationTimezone: "America/New_York",
+	})
+
+	err := ns.SendEmailNotification(context.Background(), &model.NotificationPayload{
+		Type:      "like",
+		Source:    "resume-website",
+		Timestamp: time.Date(2024, 7, 1, 16, 30, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, sender.inputs, 1) {
+		body := sender.inputs[0].Content.Simple.Body
+		assert.Contains(t, *body.Text.Data, "July 1, 2024 at 12:30 PM EDT")
+		assert.NotNil(t, body.Html)
+	}
+}
+
+func TestNotificationService_DeliveryTime(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name     string
+		quiet    config.QuietHours
+		t        time.Time
+		expected time.Time
+	}{
+		{"disabled", config.QuietHours{}, at(1, 23, 0), at(1, 23, 0)},
+		{"before overnight quiet hours", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}, at(1, 21, 59), at(1, 21, 59)},
+		{"evening quiet hours", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}, at(1, 23, 15), at(2, 7, 0)},
+		{"morning quiet hours", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}, at(2, 6, 59), at(2, 7, 0)},
+		{"after overnight quiet hours", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}, at(2, 7, 0), at(2, 7, 0)},
+		{"within same-day quiet hours", config.QuietHours{Start: 12 * time.Hour, End: 13 * time.Hour}, at(1, 12, 30), at(1, 13, 0)},
+		{"outside same-day quiet hours", config.QuietHours{Start: 12 * time.Hour, End: 13 * time.Hour}, at(1, 13, 30), at(1, 13, 30)},
+		{"night daylight saving time starts", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour},
+			time.Date(2024, 3, 9, 23, 0, 0, 0, newYork), time.Date(2024, 3, 10, 7, 0, 0, 0, newYork)},
+		{"night daylight saving time ends", config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour},
+			time.Date(2024, 11, 3, 1, 30, 0, 0, newYork), time.Date(2024, 11, 3, 7, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := NewNotificationService(nil, nil, nil, nil, &config.Config{
+				NotificationTimezone: "America/New_York",
+				QuietHours:           tt.quiet,
+			})
+			// Times are converted to the configured timezone, whatever zone they come in
+			got := ns.DeliveryTime(tt.t.UTC())
+			assert.True(t, tt.expected.Equal(got), "expected %s, got %s", tt.expected, got)
+		})
+	}
+}
+
+func TestNotificationService_IsDuplicate(t *testing.T) {
+	mockStorage := new(MockRateLimitStorage)
+	mockStorage.On("IncrementWindow", mock.Anything, mock.Anything, mock.Anything).Return(1, nil).Once()
+	mockStorage.On("IncrementWindow", mock.Anything, mock.Anything, mock.Anything).Return(2, nil)
+	mockStorage.On("GetWindow", mock.Anything, mock.Anything).Return(0, nil)
+	limiter := NewRateLimitService(mockStorage, map[string]config.RateLimitRule{
+		NotificationDedupLimiter: {Limit: 1, Window: time.Hour},
+	}, nil)
+	ns := NewNotificationService(nil, nil, limiter, nil, &config.Config{})
+
+	assert.False(t, ns.IsDuplicate(context.Background(), "like", "session-1"))
+	assert.True(t, ns.IsDuplicate(context.Background(), "like", "session-1"))
+	assert.False(t, ns.IsDuplicate(context.Background(), "like", ""), "requests without a session aren't deduplicated")
+
+	assert.False(t, NewNotificationService(nil, nil, nil, nil, &config.Config{}).IsDuplicate(context.Background(), "like", "session-1"))
+}
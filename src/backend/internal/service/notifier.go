@@ -0,0 +1,284 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"main/internal/config"
+	"main/internal/metrics"
+	"main/internal/model"
+	"main/internal/tracing"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook signature headers. The signature is an HMAC-SHA256 of "<timestamp>.<body>".
+const (
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// maxDiscordContent is Discord's limit on the length of a message
+const maxDiscordContent = 2000
+
+// Notifier delivers a notification over one channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, payload *model.NotificationPayload) error
+}
+
+type notifierRoute struct {
+	notifier Notifier
+	types    []string
+}
+
+// NotificationDispatcher fans notifications out to every channel subscribed to their type
+type NotificationDispatcher struct {
+	routes []notifierRoute
+}
+
+func NewNotificationDispatcher() *NotificationDispatcher {
+	return &NotificationDispatcher{}
+}
+
+// NewDefaultNotificationDispatcher registers every channel that is configured:
+// SES email, SNS SMS, Slack, Discord and the generic webhook
+func NewDefaultNotificationDispatcher(cfg *config.Config, ns *NotificationService, client *http.Client) *NotificationDispatcher {
+	d := NewNotificationDispatcher()
+	if cfg.NotificationDstEmail != "" && cfg.NotificationSrcEmail != "" {
+		d.Register(&emailNotifier{ns}, cfg.EmailNotificationTypes)
+	}
+	if cfg.NotificationPhoneNumber != "" {
+		d.Register(&smsNotifier{ns}, cfg.SMSNotificationTypes)
+	}
+	if cfg.SlackWebhookURL != "" {
+		d.Register(NewSlackNotifier(client, cfg.Secret("SLACK_WEBHOOK_URL")), cfg.SlackNotificationTypes)
+	}
+	if cfg.DiscordWebhookURL != "" {
+		d.Register(NewDiscordNotifier(client, cfg.Secret("DISCORD_WEBHOOK_URL")), cfg.DiscordNotificationTypes)
+	}
+	if cfg.WebhookURL != "" {
+		d.Register(NewWebhookNotifier(client, cfg.Secret("WEBHOOK_URL"), cfg.Secret("WEBHOOK_SECRET")), cfg.WebhookNotificationTypes)
+	}
+	return d
+}
+
+// Register subscribes a notifier to the given notification types
+func (d *NotificationDispatcher) Register(notifier Notifier, types []string) {
+	d.routes = append(d.routes, notifierRoute{notifier: notifier, types: types})
+}
+
+// Dispatch sends the notification to all subscribed channels concurrently. A failing
+// channel doesn't stop the others, all failures are returned together.
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, payload *model.NotificationPayload) error {
+	_, err := d.DispatchExcept(ctx, payload, nil)
+	return err
+}
+
+// DispatchExcept is Dispatch skipping the named channels, which already delivered the
+// notification. It returns the channels that delivered it on this call.
+func (d *NotificationDispatcher) DispatchExcept(ctx context.Context, payload *model.NotificationPayload, skip []string) ([]string, error) {
+	var wg sync.WaitGroup
+	errs := make([]error, len(d.routes))
+	succeeded := make([]bool, len(d.routes))
+	for i, route := range d.routes {
+		if !slices.Contains(route.types, payload.Type) || slices.Contains(skip, route.notifier.Name()) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "Notifier."+route.notifier.Name())
+			err := route.notifier.Notify(ctx, payload)
+			tracing.End(span, err)
+
+			dims := metrics.Dimensions{"Channel": route.notifier.Name(), "Type": payload.Type}
+			if err != nil {
+				metrics.Emit(dims, metrics.Count("NotificationsFailed", 1))
+				errs[i] = fmt.Errorf("%s notifier: %w", route.notifier.Name(), err)
+				return
+			}
+			metrics.Emit(dims, metrics.Count("NotificationsSent", 1))
+			succeeded[i] = true
+		}()
+	}
+	wg.Wait()
+
+	var delivered []string
+	for i, route := range d.routes {
+		if succeeded[i] {
+			delivered = append(delivered, route.notifier.Name())
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+type emailNotifier struct {
+	ns *NotificationService
+}
+
+func (n *emailNotifier) Name() string { return "email" }
+
+func (n *emailNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	return n.ns.SendEmailNotification(ctx, payload)
+}
+
+type smsNotifier struct {
+	ns *NotificationService
+}
+
+func (n *smsNotifier) Name() string { return "sms" }
+
+func (n *smsNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	return n.ns.SendSMSNotification(ctx, payload)
+}
+
+// SlackNotifier posts to a Slack incoming webhook
+type SlackNotifier struct {
+	client *http.Client
+	url    config.Secret
+}
+
+func NewSlackNotifier(client *http.Client, url config.Secret) *SlackNotifier {
+	return &SlackNotifier{client: client, url: url}
+}
+
+func (n *SlackNotifier) Name() string { return "slack" }
+
+func (n *SlackNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	text, err := notificationText(payload, slackEscape)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.url(ctx), body, nil)
+}
+
+// slackEscape escapes the characters Slack uses for links and mentions
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// DiscordNotifier posts to a Discord webhook
+type DiscordNotifier struct {
+	client *http.Client
+	url    config.Secret
+}
+
+func NewDiscordNotifier(client *http.Client, url config.Secret) *DiscordNotifier {
+	return &DiscordNotifier{client: client, url: url}
+}
+
+func (n *DiscordNotifier) Name() string { return "discord" }
+
+func (n *DiscordNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	text, err := notificationText(payload, func(s string) string { return s })
+	if err != nil {
+		return err
+	}
+	if runes := []rune(text); len(runes) > maxDiscordContent {
+		text = string(runes[:maxDiscordContent-3]) + "..."
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"content": text,
+		// Don't let message contents ping @everyone or users
+		"allowed_mentions": map[string][]string{"parse": {}},
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.url(ctx), body, nil)
+}
+
+// WebhookNotifier posts the notification payload as JSON, signed with a shared secret
+type WebhookNotifier struct {
+	client *http.Client
+	url    config.Secret
+	secret config.Secret
+	now    func() time.Time
+}
+
+func NewWebhookNotifier(client *http.Client, url, secret config.Secret) *WebhookNotifier {
+	return &WebhookNotifier{client: client, url: url, secret: secret, now: time.Now}
+}
+
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if secret := n.secret(ctx); secret != "" {
+		timestamp := strconv.FormatInt(n.now().Unix(), 10)
+		headers[webhookTimestampHeader] = timestamp
+		headers[webhookSignatureHeader] = "sha256=" + signWebhook([]byte(secret), timestamp, body)
+	}
+	return postJSON(ctx, n.client, n.url(ctx), body, headers)
+}
+
+func signWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// notificationText formats a notification for chat channels, escaping user-provided fields
+func notificationText(payload *model.NotificationPayload, escape func(string) string) (string, error) {
+	switch payload.Type {
+	case "like":
+		return fmt.Sprintf("👍 Someone liked your resume (%s)", escape(payload.Source)), nil
+	case "digest":
+		d := digestFromPayload(payload)
+		return fmt.Sprintf("📊 Resume digest: %s (totals: %d likes, %d visitors)", d, d.TotalLikes, d.TotalVisitors), nil
+	case "contact":
+		name, _ := payload.Data["name"].(string)
+		email, _ := payload.Data["email"].(string)
+		message, _ := payload.Data["message"].(string)
+
+		quoted := "> " + strings.ReplaceAll(escape(message), "\n", "\n> ")
+		return fmt.Sprintf("📬 New contact form message from %s (%s)\n%s", escape(name), escape(email), quoted), nil
+	default:
+		return "", fmt.Errorf("unknown notification type: %s", payload.Type)
+	}
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed webhook request: %v", err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", res.StatusCode)
+	}
+	return nil
+}
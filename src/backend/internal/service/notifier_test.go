@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"main/internal/config"
+	"main/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	name string
+	err  error
+
+	mu       sync.Mutex
+	payloads []*model.NotificationPayload
+}
+
+func (n *recordingNotifier) Name() string { return n.name }
+
+func (n *recordingNotifier) Notify(ctx context.Context, payload *model.NotificationPayload) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.payloads = append(n.payloads, payload)
+	return n.err
+}
+
+func TestNotificationDispatcher_Dispatch(t *testing.T) {
+	slack := &recordingNotifier{name: "slack"}
+	sms := &recordingNotifier{name: "sms"}
+	broken := &recordingNotifier{name: "broken", err: errors.New("unavailable")}
+
+	d := NewNotificationDispatcher()
+	d.Register(slack, []string{"like", "contact"})
+	d.Register(sms, []string{"contact"})
+	d.Register(broken, []string{"contact"})
+
+	err := d.Dispatch(context.Background(), &model.NotificationPayload{Type: "like"})
+	assert.NoError(t, err)
+	assert.Len(t, slack.payloads, 1)
+	assert.Empty(t, sms.payloads)
+
+	err = d.Dispatch(context.Background(), &model.NotificationPayload{Type: "contact"})
+	assert.ErrorContains(t, err, "broken notifier: unavailable")
+	assert.Len(t, slack.payloads, 2)
+	assert.Len(t, sms.payloads, 1)
+}
+
+// captureServer records the last request body and headers it received
+func captureServer(t *testing.T, status int) (*httptest.Server, func() (map[string]any, http.Header)) {
+	var mu sync.Mutex
+	var body map[string]any
+	var headers http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = r.Header.Clone()
+		raw, _ := io.ReadAll(r.Body)
+		body = nil
+		json.Unmarshal(raw, &body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() (map[string]any, http.Header) {
+		mu.Lock()
+		defer mu.Unlock()
+		return body, headers
+	}
+}
+
+var testContactPayload = &model.NotificationPayload{
+	Type:   "contact",
+	Source: "resume-website",
+	Data: map[string]any{
+		"name":    "Jane <@here>",
+		"email":   "jane@example.com",
+		"message": "Hi,\nlet's talk",
+	},
+	Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, last := captureServer(t, http.StatusOK)
+
+	err := NewSlackNotifier(server.Client(), config.StaticSecret(server.URL)).Notify(context.Background(), testContactPayload)
+	assert.NoError(t, err)
+
+	body, _ := last()
+	text, _ := body["text"].(string)
+	assert.Contains(t, text, "Jane &lt;@here&gt;")
+	assert.Contains(t, text, "> Hi,\n> let's talk")
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	server, last := captureServer(t, http.StatusNoContent)
+
+	payload := *testContactPayload
+	payload.Data = map[string]any{"name": "Jane", "email": "jane@example.com", "message": strings.Repeat("a", 3000)}
+	err := NewDiscordNotifier(server.Client(), config.StaticSecret(server.URL)).Notify(context.Background(), &payload)
+	assert.NoError(t, err)
+
+	body, _ := last()
+	content, _ := body["content"].(string)
+	assert.Len(t, []rune(content), maxDiscordContent)
+	assert.Equal(t, map[string]any{"parse": []any{}}, body["allowed_mentions"])
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("signs the payload", func(t *testing.T) {
+		server, last := captureServer(t, http.StatusOK)
+
+		n := NewWebhookNotifier(server.Client(), config.StaticSecret(server.URL), config.StaticSecret("secret"))
+		n.now = func() time.Time { return time.Unix(1700000000, 0) }
+		err := n.Notify(context.Background(), testContactPayload)
+		assert.NoError(t, err)
+
+		body, headers := last()
+		assert.Equal(t, "contact", body["type"])
+		assert.Equal(t, "1700000000", headers.Get(webhookTimestampHeader))
+
+		raw, _ := json.Marshal(testContactPayload)
+		expected := "sha256=" + signWebhook([]byte("secret"), "1700000000", raw)
+		assert.Equal(t, expected, headers.Get(webhookSignatureHeader))
+	})
+
+	t.Run("unsigned without a secret", func(t *testing.T) {
+		server, last := captureServer(t, http.StatusOK)
+
+		err := NewWebhookNotifier(server.Client(), config.StaticSecret(server.URL), config.StaticSecret("")).Notify(context.Background(), testContactPayload)
+		assert.NoError(t, err)
+
+		_, headers := last()
+		assert.Empty(t, headers.Get(webhookSignatureHeader))
+	})
+
+	t.Run("returns error on failure status", func(t *testing.T) {
+		server, _ := captureServer(t, http.StatusInternalServerError)
+
+		err := NewWebhookNotifier(server.Client(), config.StaticSecret(server.URL), config.StaticSecret("secret")).Notify(context.Background(), testContactPayload)
+		assert.ErrorContains(t, err, "status 500")
+	})
+}
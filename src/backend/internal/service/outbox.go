@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// outboxLease is how long a claimed entry is hidden from other workers while it is delivered
+const outboxLease = 2 * time.Minute
+
+// outboxRetention is how long delivered entries are kept before DynamoDB expires them
+const outboxRetention = 7 * 24 * time.Hour
+
+// OutboxService queues notifications in DynamoDB so they survive the request that created them,
+// and delivers them from the worker with exponential backoff. Notifications created during quiet
+// hours are held until the quiet hours end. Without storage, notifications are delivered directly
+// in the request.
+type OutboxService struct {
+	storage       storage.OutboxStorageInterface
+	dispatcher    *NotificationDispatcher
+	notifications *NotificationService
+	maxAttempts   int
+	baseDelay     time.Duration
+	maxDelay      time.Duration
+	now           func() time.Time
+}
+
+func NewOutboxService(storage storage.OutboxStorageInterface, dispatcher *NotificationDispatcher, notifications *NotificationService, cfg *config.Config) *OutboxService {
+	return &OutboxService{
+		storage:       storage,
+		dispatcher:    dispatcher,
+		notifications: notifications,
+		maxAttempts:   cfg.OutboxMaxAttempts,
+		baseDelay:     cfg.OutboxBaseDelay,
+		maxDelay:      cfg.OutboxMaxDelay,
+		now:           time.Now,
+	}
+}
+
+// Notify queues a notification for the worker. If it can't be queued, it is delivered directly.
+func (ob *OutboxService) Notify(ctx context.Context, payload *model.NotificationPayload) (err error) {
+	ctx, span := tracing.Start(ctx, "OutboxService.Notify")
+	defer func() { tracing.End(span, err) }()
+
+	if ob.storage == nil {
+		// Nowhere to hold the notification during quiet hours
+		return ob.dispatcher.Dispatch(ctx, payload)
+	}
+
+	now := ob.now()
+	deliverAt := now
+	if ob.notifications != nil {
+		deliverAt = ob.notifications.DeliveryTime(now)
+	}
+	entry := &model.OutboxEntry{
+		ID:            generateOutboxID(),
+		Status:        model.OutboxStatusPending,
+		Payload:       *payload,
+		CreatedAt:     now,
+		NextAttemptAt: deliverAt,
+	}
+	if err := ob.storage.CreateOutboxEntry(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "Couldn't queue notification, delivering directly", "type", payload.Type, "error", err)
+		return ob.dispatcher.Dispatch(ctx, payload)
+	}
+	return nil
+}
+
+// ProcessDue delivers up to limit entries that are due, returning how many were attempted
+func (ob *OutboxService) ProcessDue(ctx context.Context, limit int32) (attempted int, err error) {
+	ctx, span := tracing.Start(ctx, "OutboxService.ProcessDue")
+	defer func() { tracing.End(span, err) }()
+
+	if ob.storage == nil {
+		return 0, nil
+	}
+
+	entries, err := ob.storage.ListDueOutboxEntries(ctx, ob.now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list outbox entries: %w", err)
+	}
+
+	for i := range entries {
+		ok, err := ob.process(ctx, &entries[i])
+		if err != nil {
+			slog.ErrorContext(ctx, "Error processing outbox entry", "entry_id", entries[i].ID, "error", err)
+		}
+		if ok {
+			attempted++
+		}
+	}
+	return attempted, nil
+}
+
+// HandleStreamEvent delivers entries as soon as they are inserted, using the table's DynamoDB stream.
+// Failures are left for ProcessDue to retry, so the stream batch itself never fails.
+func (ob *OutboxService) HandleStreamEvent(ctx context.Context, event events.DynamoDBEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "OutboxService.HandleStreamEvent")
+	defer func() { tracing.End(span, err) }()
+
+	if ob.storage == nil {
+		return nil
+	}
+
+	for _, record := range event.Records {
+		if record.EventName != "INSERT" {
+			continue
+		}
+		id := record.Change.Keys["ID"].String()
+
+		entry, err := ob.storage.GetOutboxEntry(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error loading outbox entry", "entry_id", id, "error", err)
+			continue
+		}
+		if entry == nil || entry.Status != model.OutboxStatusPending || entry.NextAttemptAt.After(ob.now()) {
+			continue
+		}
+		if _, err := ob.process(ctx, entry); err != nil {
+			slog.ErrorContext(ctx, "Error processing outbox entry", "entry_id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+// process claims an entry and attempts delivery on the channels that haven't delivered it yet.
+// It returns false if another worker claimed the entry first.
+func (ob *OutboxService) process(ctx context.Context, entry *model.OutboxEntry) (bool, error) {
+	prevAttempts := entry.Attempts
+	entry.Attempts++
+	entry.NextAttemptAt = ob.now().Add(outboxLease)
+	if err := ob.storage.UpdateOutboxEntry(ctx, entry, prevAttempts); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim entry: %w", err)
+	}
+
+	delivered, dispatchErr := ob.dispatcher.DispatchExcept(ctx, &entry.Payload, entry.Delivered)
+	entry.Delivered = append(entry.Delivered, delivered...)
+
+	now := ob.now()
+	switch {
+	case dispatchErr == nil:
+		entry.Status = model.OutboxStatusDelivered
+		entry.LastError = ""
+		expiresAt := now.Add(outboxRetention)
+		entry.ExpiresAt = &expiresAt
+	case entry.Attempts >= ob.maxAttempts:
+		entry.Status = model.OutboxStatusDead
+		entry.LastError = dispatchErr.Error()
+		slog.WarnContext(ctx, "Giving up on outbox entry", "entry_id", entry.ID, "attempts", entry.Attempts, "error", dispatchErr)
+	default:
+		entry.LastError = dispatchErr.Error()
+		entry.NextAttemptAt = now.Add(ob.backoff(entry.Attempts))
+	}
+
+	if err := ob.storage.UpdateOutboxEntry(ctx, entry, entry.Attempts); err != nil {
+		return true, fmt.Errorf("failed to save delivery result: %w", err)
+	}
+	return true, nil
+}
+
+// backoff returns the delay before the next attempt, doubling after every failed attempt
+func (ob *OutboxService) backoff(attempts int) time.Duration {
+	delay := ob.baseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= ob.maxDelay {
+			return ob.maxDelay
+		}
+	}
+	return min(delay, ob.maxDelay)
+}
+
+func generateOutboxID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
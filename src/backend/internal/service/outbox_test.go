@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxStorage struct {
+	mock.Mock
+	updates []model.OutboxEntry // copies of every updated entry
+}
+
+func (m *MockOutboxStorage) CreateOutboxEntry(ctx context.Context, entry *model.OutboxEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStorage) GetOutboxEntry(ctx context.Context, id string) (*model.OutboxEntry, error) {
+	args := m.Called(ctx, id)
+	if entry, ok := args.Get(0).(*model.OutboxEntry); ok {
+		return entry, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxStorage) ListDueOutboxEntries(ctx context.Context, now time.Time, limit int32) ([]model.OutboxEntry, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]model.OutboxEntry), args.Error(1)
+}
+
+func (m *MockOutboxStorage) UpdateOutboxEntry(ctx context.Context, entry *model.OutboxEntry, prevAttempts int) error {
+	m.updates = append(m.updates, *entry)
+	args := m.Called(ctx, entry, prevAttempts)
+	return args.Error(0)
+}
+
+func testOutboxConfig() *config.Config {
+	return &config.Config{
+		OutboxMaxAttempts: 3,
+		OutboxBaseDelay:   30 * time.Second,
+		OutboxMaxDelay:    time.Hour,
+	}
+}
+
+func newTestOutbox(store storage.OutboxStorageInterface, notifiers ...*recordingNotifier) (*OutboxService, time.Time) {
+	dispatcher := NewNotificationDispatcher()
+	for _, n := range notifiers {
+		dispatcher.Register(n, []string{"contact"})
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ob := NewOutboxService(store, dispatcher, nil, testOutboxConfig())
+	ob.now = func() time.Time { return now }
+	return ob, now
+}
+
+func TestOutboxService_Notify(t *testing.T) {
+	payload := &model.NotificationPayload{Type: "contact"}
+
+	t.Run("queues the notification", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("CreateOutboxEntry", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+			return entry.ID != "" && entry.Status == model.OutboxStatusPending && entry.Payload.Type == "contact"
+		})).Return(nil)
+		slack := &recordingNotifier{name: "slack"}
+		ob, _ := newTestOutbox(store, slack)
+
+		err := ob.Notify(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Empty(t, slack.payloads)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("delivers directly when queueing fails", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("CreateOutboxEntry", mock.Anything, mock.Anything).Return(errors.New("table missing"))
+		slack := &recordingNotifier{name: "slack"}
+		ob, _ := newTestOutbox(store, slack)
+
+		err := ob.Notify(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Len(t, slack.payloads, 1)
+	})
+
+	t.Run("delivers directly without storage", func(t *testing.T) {
+		slack := &recordingNotifier{name: "slack"}
+		ob, _ := newTestOutbox(nil, slack)
+
+		err := ob.Notify(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Len(t, slack.payloads, 1)
+	})
+}
+
+func TestOutboxService_ProcessDue(t *testing.T) {
+	pendingEntry := func(attempts int, delivered ...string) model.OutboxEntry {
+		return model.OutboxEntry{
+			ID:        "entry-1",
+			Status:    model.OutboxStatusPending,
+			Payload:   model.NotificationPayload{Type: "contact"},
+			Attempts:  attempts,
+			Delivered: delivered,
+		}
+	}
+
+	t.Run("marks delivered entries", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("ListDueOutboxEntries", mock.Anything, mock.Anything, int32(10)).Return([]model.OutboxEntry{pendingEntry(0)}, nil)
+		store.On("UpdateOutboxEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		slack := &recordingNotifier{name: "slack"}
+		ob, now := newTestOutbox(store, slack)
+
+		attempted, err := ob.ProcessDue(context.Background(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+		assert.Len(t, slack.payloads, 1)
+
+		store.AssertCalled(t, "UpdateOutboxEntry", mock.Anything, mock.Anything, 0) // claim
+		store.AssertCalled(t, "UpdateOutboxEntry", mock.Anything, mock.Anything, 1) // result
+		final := store.updates[1]
+		assert.Equal(t, model.OutboxStatusDelivered, final.Status)
+		assert.Equal(t, []string{"slack"}, final.Delivered)
+		assert.Equal(t, now.Add(outboxRetention), *final.ExpiresAt)
+	})
+
+	t.Run("retries failed channels with backoff", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("ListDueOutboxEntries", mock.Anything, mock.Anything, mock.Anything).Return([]model.OutboxEntry{pendingEntry(1, "email")}, nil)
+		store.On("UpdateOutboxEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		email := &recordingNotifier{name: "email"}
+		slack := &recordingNotifier{name: "slack", err: errors.New("webhook down")}
+		sms := &recordingNotifier{name: "sms"}
+		ob, now := newTestOutbox(store, email, slack, sms)
+
+		_, err := ob.ProcessDue(context.Background(), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, email.payloads, "already delivered channels are skipped")
+
+		final := store.updates[1]
+		assert.Equal(t, model.OutboxStatusPending, final.Status)
+		assert.Equal(t, 2, final.Attempts)
+		assert.ElementsMatch(t, []string{"email", "sms"}, final.Delivered)
+		assert.Contains(t, final.LastError, "webhook down")
+		assert.Equal(t, now.Add(time.Minute), final.NextAttemptAt)
+	})
+
+	t.Run("dead-letters after max attempts", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("ListDueOutboxEntries", mock.Anything, mock.Anything, mock.Anything).Return([]model.OutboxEntry{pendingEntry(2)}, nil)
+		store.On("UpdateOutboxEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		slack := &recordingNotifier{name: "slack", err: errors.New("webhook down")}
+		ob, _ := newTestOutbox(store, slack)
+
+		_, err := ob.ProcessDue(context.Background(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, model.OutboxStatusDead, store.updates[1].Status)
+	})
+
+	t.Run("skips entries claimed by another worker", func(t *testing.T) {
+		store := new(MockOutboxStorage)
+		store.On("ListDueOutboxEntries", mock.Anything, mock.Anything, mock.Anything).Return([]model.OutboxEntry{pendingEntry(0)}, nil)
+		store.On("UpdateOutboxEntry", mock.Anything, mock.Anything, 0).Return(storage.ErrConflict)
+		slack := &recordingNotifier{name: "slack"}
+		ob, _ := newTestOutbox(store, slack)
+
+		attempted, err := ob.ProcessDue(context.Background(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, attempted)
+		assert.Empty(t, slack.payloads)
+	})
+}
+
+func TestOutboxService_HandleStreamEvent(t *testing.T) {
+	store := new(MockOutboxStorage)
+	store.On("GetOutboxEntry", mock.Anything, "entry-1").Return(&model.OutboxEntry{
+		ID:      "entry-1",
+		Status:  model.OutboxStatusPending,
+		Payload: model.NotificationPayload{Type: "contact"},
+	}, nil)
+	store.On("UpdateOutboxEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	slack := &recordingNotifier{name: "slack"}
+	ob, _ := newTestOutbox(store, slack)
+
+	keys := map[string]events.DynamoDBAttributeValue{"ID": events.NewStringAttribute("entry-1")}
+	err := ob.HandleStreamEvent(context.Background(), events.DynamoDBEvent{
+		Records: []events.DynamoDBEventRecord{
+			{EventName: "INSERT", Change: events.DynamoDBStreamRecord{Keys: keys}},
+			{EventName: "MODIFY", Change: events.DynamoDBStreamRecord{Keys: keys}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, slack.payloads, 1)
+	store.AssertNumberOfCalls(t, "GetOutboxEntry", 1)
+}
+
+func TestOutboxService_Backoff(t *testing.T) {
+	ob, _ := newTestOutbox(nil)
+
+	assert.Equal(t, 30*time.Second, ob.backoff(1))
+	assert.Equal(t, time.Minute, ob.backoff(2))
+	assert.Equal(t, 4*time.Minute, ob.backoff(4))
+	assert.Equal(t, time.Hour, ob.backoff(20))
+}
+
+func TestOutboxService_NotifyDuringQuietHours(t *testing.T) {
+	store := new(MockOutboxStorage)
+	store.On("CreateOutboxEntry", mock.Anything, mock.Anything).Return(nil)
+
+	ns := NewNotificationService(nil, nil, nil, nil, &config.Config{
+		NotificationTimezone: "UTC",
+		QuietHours:           config.QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour},
+	})
+	ob := NewOutboxService(store, NewNotificationDispatcher(), ns, testOutboxConfig())
+	ob.now = func() time.Time { return time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC) }
+
+	err := ob.Notify(context.Background(), &model.NotificationPayload{Type: "contact"})
+	assert.NoError(t, err)
+	store.AssertCalled(t, "CreateOutboxEntry", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.NextAttemptAt.Equal(time.Date(2024, 1, 2, 7, 0, 0, 0, time.UTC))
+	}))
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"main/internal/config"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"time"
+)
+
+// AutoReplyLimiter is the rule name limiting acknowledgment emails per recipient address
+const AutoReplyLimiter = "auto_reply"
+
+type RateLimitService struct {
+	storage   storage.RateLimitStorageInterface
+	rules     map[string]config.RateLimitRule
+	blocklist *BlocklistService
+	now       func() time.Time
+}
+
+// NewRateLimitService creates a rate limiter that also turns away blocklisted IPs, if a blocklist is given
+func NewRateLimitService(storage storage.RateLimitStorageInterface, rules map[string]config.RateLimitRule, blocklist *BlocklistService) *RateLimitService {
+	return &RateLimitService{
+		storage:   storage,
+		rules:     rules,
+		blocklist: blocklist,
+		now:       time.Now,
+	}
+}
+
+// Blocked reports whether the source IP is on the blocklist
+func (rs *RateLimitService) Blocked(ctx context.Context, sourceIP string) (blocked bool, err error) {
+	ctx, span := tracing.Start(ctx, "RateLimitService.Blocked")
+	defer func() { tracing.End(span, err) }()
+
+	if rs.blocklist == nil {
+		return false, nil
+	}
+	return rs.blocklist.IsIPBlocked(ctx, sourceIP)
+}
+
+// Allow records a hit on route and reports whether the source IP and the session are still within
+// the route's limit. When a limit is exceeded it also returns how long the caller should wait.
+// Routes without a rule are never limited.
+func (rs *RateLimitService) Allow(ctx context.Context, route, sourceIP, sessionID string) (allowed bool, retryAfter time.Duration, err error) {
+	ctx, span := tracing.Start(ctx, "RateLimitService.Allow")
+	defer func() { tracing.End(span, err) }()
+
+	rule, ok := rs.rules[route]
+	if !ok {
+		return true, 0, nil
+	}
+
+	var keys []string
+	if sourceIP != "" {
+		keys = append(keys, "ip:"+hashIdentifier(sourceIP))
+	}
+	if sessionID != "" {
+		keys = append(keys, "session:"+hashIdentifier(sessionID))
+	}
+
+	allowed = true
+	for _, key := range keys {
+		ok, wait, err := rs.check(ctx, route+"#"+key, rule)
+		if err != nil {
+			return true, 0, err
+		}
+		if !ok {
+			allowed = false
+			retryAfter = max(retryAfter, wait)
+		}
+	}
+	return allowed, retryAfter, nil
+}
+
+// AllowKey records a hit for an arbitrary key, such as an email address, under a named rule.
+// Names without a rule are never limited.
+func (rs *RateLimitService) AllowKey(ctx context.Context, name, key string) (bool, time.Duration, error) {
+	rule, ok := rs.rules[name]
+	if !ok {
+		return true, 0, nil
+	}
+	return rs.check(ctx, name+"#key:"+hashIdentifier(key), rule)
+}
+
+// check implements a sliding window counter: the hits of the previous fixed window are
+// weighted by how much of it still overlaps the sliding window ending now. Rejected hits
+// count too, and the returned wait is how long until one more hit fits in the estimate.
+func (rs *RateLimitService) check(ctx context.Context, key string, rule config.RateLimitRule) (bool, time.Duration, error) {
+	now := rs.now()
+	windowStart := now.Truncate(rule.Window)
+	prevStart := windowStart.Add(-rule.Window)
+
+	current, err := rs.storage.IncrementWindow(ctx, windowKey(key, windowStart), windowStart.Add(2*rule.Window))
+	if err != nil {
+		return false, 0, err
+	}
+
+	previous, err := rs.storage.GetWindow(ctx, windowKey(key, prevStart))
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Compare in milliseconds of the window, so that rounding can't tip a hit at the limit over it
+	window := rule.Window.Milliseconds()
+	elapsed := now.Sub(windowStart).Milliseconds()
+	limit := int64(rule.Limit)
+	if int64(previous)*(window-elapsed)+int64(current)*window <= limit*window {
+		return true, 0, nil
+	}
+
+	wait := retryAt(window, limit, int64(previous), int64(current)) - elapsed
+	return false, time.Duration((wait+999)/1000) * time.Second, nil
+}
+
+// retryAt returns the time, in milliseconds from the start of the current window, at which one
+// more hit fits in the sliding window estimate, assuming no other hits are counted before then
+func retryAt(window, limit, previous, current int64) int64 {
+	// Later in the current window, once enough of the previous window has slid out
+	if room := limit - current - 1; room >= 0 && previous > 0 {
+		return window - room*window/previous
+	}
+	// In the next window, where the current window's hits are the previous ones
+	if current == 0 {
+		return window
+	}
+	room := max(limit-1, 0)
+	return window + max(window-room*window/current, 0)
+}
+
+func windowKey(key string, windowStart time.Time) string {
+	return fmt.Sprintf("%s#%d", key, windowStart.Unix())
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimitStorage struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitStorage) IncrementWindow(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	args := m.Called(ctx, key, expiresAt)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRateLimitStorage) GetWindow(ctx context.Context, key string) (int, error) {
+	args := m.Called(ctx, key)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRateLimitService_Allow(t *testing.T) {
+	rules := map[string]config.RateLimitRule{
+		"/api/contact": {Limit: 5, Window: time.Hour},
+	}
+	// 45 minutes into the current window, so a quarter of the previous window still counts
+	now := time.Date(2025, 1, 1, 10, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		route         string
+		sessionID     string
+		mockSetup     func(*MockRateLimitStorage)
+		expectedAllow bool
+		expectedWait  time.Duration
+		expectedError bool
+	}{
+		{
+			name:  "route without rule",
+			route: "/api/getLikeCount",
+			mockSetup: func(m *MockRateLimitStorage) {
+			},
+			expectedAllow: true,
+		},
+		{
+			name:  "under limit",
+			route: "/api/contact",
+			mockSetup: func(m *MockRateLimitStorage) {
+				m.On("IncrementWindow", mock.Anything, mock.Anything, mock.Anything).Return(2, nil)
+				m.On("GetWindow", mock.Anything, mock.Anything).Return(8, nil)
+			},
+			expectedAllow: true,
+		},
+		{
+			name:  "previous window pushes over limit",
+			route: "/api/contact",
+			mockSetup: func(m *MockRateLimitStorage) {
+				m.On("IncrementWindow", mock.Anything, mock.Anything, mock.Anything).Return(4, nil)
+				m.On("GetWindow", mock.Anything, mock.Anything).Return(8, nil)
+			},
+			expectedAllow: false,
+			expectedWait:  15 * time.Minute,
+		},
+		{
+			name:      "session over limit while ip is not",
+			route:     "/api/contact",
+			sessionID: "test-session",
+			mockSetup: func(m *MockRateLimitStorage) {
+				m.On("IncrementWindow", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return key[:len("/api/contact#ip:")] == "/api/contact#ip:"
+				}), mock.Anything).Return(1, nil)
+				m.On("IncrementWindow", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return key[:len("/api/contact#session:")] == "/api/contact#session:"
+				}), mock.Anything).Return(6, nil)
+				m.On("GetWindow", mock.Anything, mock.Anything).Return(0, nil)
+			},
+			expectedAllow: false,
+			// The 6 hits only slide out far enough 20 minutes into the next window
+			expectedWait: 35 * time.Minute,
+		},
+		{
+			name:  "storage error fails open",
+			route: "/api/contact",
+			mockSetup: func(m *MockRateLimitStorage) {
+				m.On("IncrementWindow", mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("storage error"))
+			},
+			expectedAllow: true,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockRateLimitStorage{}
+			tt.mockSetup(mockStorage)
+
+			service := NewRateLimitService(mockStorage, rules, nil)
+			service.now = func() time.Time { return now }
+			allowed, wait, err := service.Allow(context.Background(), tt.route, "203.0.113.7", tt.sessionID)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAllow, allowed)
+			assert.Equal(t, tt.expectedWait, wait)
+
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+// memoryRateLimitStorage counts hits per window in memory
+type memoryRateLimitStorage struct {
+	windows map[string]int
+}
+
+func (m *memoryRateLimitStorage) IncrementWindow(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	m.windows[key]++
+	return m.windows[key], nil
+}
+
+func (m *memoryRateLimitStorage) GetWindow(ctx context.Context, key string) (int, error) {
+	return m.windows[key], nil
+}
+
+func TestRateLimitService_RetryAfter(t *testing.T) {
+	rules := map[string]config.RateLimitRule{
+		"/api/contact": {Limit: 5, Window: time.Hour},
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		hits  int
+	}{
+		{name: "rejected at the end of the window", start: time.Date(2025, 1, 1, 10, 45, 0, 0, time.UTC), hits: 6},
+		{name: "hammered past the limit", start: time.Date(2025, 1, 1, 10, 45, 0, 0, time.UTC), hits: 12},
+		{name: "previous window still counts", start: time.Date(2025, 1, 1, 10, 55, 0, 0, time.UTC), hits: 8},
+		{name: "uneven window position", start: time.Date(2025, 1, 1, 10, 7, 13, 0, time.UTC), hits: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var now time.Time
+			service := NewRateLimitService(nil, rules, nil)
+			service.now = func() time.Time { return now }
+			allow := func() bool {
+				allowed, _, err := service.Allow(context.Background(), "/api/contact", "203.0.113.7", "")
+				assert.NoError(t, err)
+				return allowed
+			}
+
+			// hit sends the hits a minute apart from the start and returns the last wait
+			hit := func() time.Duration {
+				service.storage = &memoryRateLimitStorage{windows: map[string]int{}}
+				now = tt.start
+				var allowed bool
+				var wait time.Duration
+				for i := range tt.hits {
+					now = tt.start.Add(time.Duration(i) * time.Minute)
+					allowed, wait, _ = service.Allow(context.Background(), "/api/contact", "203.0.113.7", "")
+				}
+				assert.False(t, allowed)
+				return wait
+			}
+
+			wait := hit()
+			assert.Positive(t, wait)
+			last := now
+
+			now = last.Add(wait - time.Second)
+			assert.False(t, allow(), "the wait isn't longer than needed")
+
+			// Waiting as long as Retry-After says lets the next request through
+			hit()
+			now = last.Add(wait)
+			assert.True(t, allow())
+		})
+	}
+}
+
+func TestRateLimitService_AllowKey(t *testing.T) {
+	rules := map[string]config.RateLimitRule{
+		AutoReplyLimiter: {Limit: 1, Window: 24 * time.Hour},
+	}
+
+	mockStorage := &MockRateLimitStorage{}
+	mockStorage.On("IncrementWindow", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return key[:len("auto_reply#key:")] == "auto_reply#key:"
+	}), mock.Anything).Return(2, nil)
+	mockStorage.On("GetWindow", mock.Anything, mock.Anything).Return(0, nil)
+
+	service := NewRateLimitService(mockStorage, rules, nil)
+	allowed, _, err := service.AllowKey(context.Background(), AutoReplyLimiter, "jane@example.com")
+
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	mockStorage.AssertExpectations(t)
+
+	allowed, _, err = service.AllowKey(context.Background(), "unknown", "jane@example.com")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"main/internal/model"
 	"main/internal/storage"
+	"main/internal/tracing"
 )
 
 type SessionService struct {
@@ -34,13 +35,16 @@ func (ss *SessionService) createNewSession(ctx context.Context) (*model.UserSess
 }
 
 // GetOrCreateSession returns existing session or creates a new one with default values
-func (ss *SessionService) GetOrCreateSession(ctx context.Context, sessionID string) (*model.UserSession, bool, error) {
+func (ss *SessionService) GetOrCreateSession(ctx context.Context, sessionID string) (session *model.UserSession, created bool, err error) {
+	ctx, span := tracing.Start(ctx, "SessionService.GetOrCreateSession")
+	defer func() { tracing.End(span, err) }()
+
 	if sessionID == "" {
 		return ss.createNewSession(ctx) // No existing session, create a new one
 	}
 
 	// Try to get existing session
-	session, err := ss.storage.GetUserSession(ctx, sessionID)
+	session, err = ss.storage.GetUserSession(ctx, sessionID)
 	if err != nil {
 		return nil, false, err
 	}
@@ -53,12 +57,18 @@ func (ss *SessionService) GetOrCreateSession(ctx context.Context, sessionID stri
 }
 
 // UpdateSession updates an existing session
-func (ss *SessionService) UpdateSession(ctx context.Context, session *model.UserSession) error {
+func (ss *SessionService) UpdateSession(ctx context.Context, session *model.UserSession) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionService.UpdateSession")
+	defer func() { tracing.End(span, err) }()
+
 	return ss.storage.UpdateUserSession(ctx, session)
 }
 
 // ValidateSession checks if a session exists and is valid
-func (ss *SessionService) ValidateSession(ctx context.Context, sessionID string) (*model.UserSession, error) {
+func (ss *SessionService) ValidateSession(ctx context.Context, sessionID string) (session *model.UserSession, err error) {
+	ctx, span := tracing.Start(ctx, "SessionService.ValidateSession")
+	defer func() { tracing.End(span, err) }()
+
 	if sessionID == "" {
 		return nil, nil
 	}
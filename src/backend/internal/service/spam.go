@@ -0,0 +1,300 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/tracing"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed data/disposable_domains.txt
+var defaultDisposableDomains string
+
+// DuplicateMessageLimiter is the rule name used to detect the same message being sent repeatedly
+const DuplicateMessageLimiter = "duplicate_message"
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.|\[url`)
+
+// SpamInput is what spam checks look at for a contact message
+type SpamInput struct {
+	Request      *model.ContactRequest
+	SessionID    string
+	CaptchaScore float64
+}
+
+// SpamSignal is a suspicious trait found by a check and the score it adds
+type SpamSignal struct {
+	Check  string  `json:"check"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// SpamCheck inspects a message and returns a signal, or nil when nothing is suspicious
+type SpamCheck interface {
+	Check(ctx context.Context, input *SpamInput) (*SpamSignal, error)
+}
+
+// SpamVerdict is the combined result of all checks
+type SpamVerdict struct {
+	Score      float64
+	Signals    []SpamSignal
+	Quarantine bool
+}
+
+type SpamScorer struct {
+	checks     []SpamCheck
+	threshold  float64
+	formTiming *FormTimingCheck
+}
+
+func NewSpamScorer(threshold float64, checks ...SpamCheck) *SpamScorer {
+	return &SpamScorer{checks: checks, threshold: threshold}
+}
+
+// NewDefaultSpamScorer builds the pipeline configured for the contact form.
+// Duplicate messages are counted with the rate limiter's duplicate_message rule.
+func NewDefaultSpamScorer(cfg *config.Config, rateLimiter *RateLimitService) (*SpamScorer, error) {
+	var patterns []*regexp.Regexp
+	for _, p := range cfg.SpamBlockedPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spam pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	checks := []SpamCheck{
+		HoneypotCheck{},
+		LinkCountCheck{MaxLinks: cfg.SpamMaxLinks},
+		BlocklistCheck{Keywords: cfg.SpamBlockedKeywords, Patterns: patterns},
+		NewDisposableEmailCheck(parseDomainList(defaultDisposableDomains)),
+		CaptchaScoreCheck{MinScore: 0.7},
+		DuplicateMessageCheck{rateLimiter: rateLimiter},
+	}
+
+	// Without a secret no form token could be verified, and every message would be penalized
+	var formTiming *FormTimingCheck
+	if cfg.FormTokenSecret != "" {
+		formTiming = NewFormTimingCheck(cfg.Secret("FORM_TOKEN_SECRET"), cfg.SpamMinFillTime, cfg.SpamMaxFormAge)
+		checks = append(checks, formTiming)
+	}
+
+	scorer := NewSpamScorer(cfg.SpamThreshold, checks...)
+	scorer.formTiming = formTiming
+	return scorer, nil
+}
+
+// IssueFormToken returns the token the contact form must send back for the fill time check,
+// empty when the check isn't part of the pipeline
+func (ss *SpamScorer) IssueFormToken(ctx context.Context, sessionID string) string {
+	if ss.formTiming == nil {
+		return ""
+	}
+	return ss.formTiming.IssueToken(ctx, sessionID)
+}
+
+// Score runs every check and sums their scores. Failing checks are skipped so that
+// an outage of one check doesn't block the contact form.
+func (ss *SpamScorer) Score(ctx context.Context, input *SpamInput) *SpamVerdict {
+	ctx, span := tracing.Start(ctx, "SpamScorer.Score")
+	defer span.End()
+
+	verdict := &SpamVerdict{}
+	for _, check := range ss.checks {
+		signal, err := check.Check(ctx, input)
+		if err != nil {
+			slog.WarnContext(ctx, "Spam check failed", "check", fmt.Sprintf("%T", check), "error", err)
+			continue
+		}
+		if signal != nil {
+			verdict.Score += signal.Score
+			verdict.Signals = append(verdict.Signals, *signal)
+		}
+	}
+	verdict.Quarantine = verdict.Score >= ss.threshold
+	return verdict
+}
+
+// HoneypotCheck flags messages filling the hidden field that only bots see
+type HoneypotCheck struct{}
+
+func (HoneypotCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	if input.Request.Website != "" {
+		return &SpamSignal{Check: "honeypot", Score: 10}, nil
+	}
+	return nil, nil
+}
+
+// FormTimingCheck flags forms submitted faster than a human can fill them in, based on
+// a signed timestamp token handed out with the session. Tokens only count for the session
+// they were issued to and until they are maxAge old, so one token can't be replayed.
+type FormTimingCheck struct {
+	secret      config.Secret
+	minFillTime time.Duration
+	maxAge      time.Duration
+	now         func() time.Time
+}
+
+func NewFormTimingCheck(secret config.Secret, minFillTime, maxAge time.Duration) *FormTimingCheck {
+	return &FormTimingCheck{secret: secret, minFillTime: minFillTime, maxAge: maxAge, now: time.Now}
+}
+
+// IssueToken returns a token recording when the form was served to a session
+func (fc *FormTimingCheck) IssueToken(ctx context.Context, sessionID string) string {
+	ts := strconv.FormatInt(fc.now().UnixMilli(), 10)
+	return ts + "." + signFormToken(fc.secret(ctx), sessionID, ts)
+}
+
+func signFormToken(secret, sessionID, ts string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(sessionID + "." + ts))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (fc *FormTimingCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	invalid := &SpamSignal{Check: "form_timing", Score: 3, Detail: "missing or invalid form token"}
+
+	secret := fc.secret(ctx)
+	ts, sig, ok := strings.Cut(input.Request.FormToken, ".")
+	if !ok || secret == "" || input.SessionID == "" || !hmac.Equal([]byte(signFormToken(secret, input.SessionID, ts)), []byte(sig)) {
+		return invalid, nil
+	}
+
+	issuedAt, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return invalid, nil
+	}
+
+	elapsed := fc.now().Sub(time.UnixMilli(issuedAt))
+	switch {
+	case elapsed < 0 || elapsed > fc.maxAge:
+		return invalid, nil
+	case elapsed < fc.minFillTime:
+		return &SpamSignal{Check: "form_timing", Score: 5, Detail: fmt.Sprintf("filled in %s", elapsed.Round(time.Millisecond))}, nil
+	}
+	return nil, nil
+}
+
+// LinkCountCheck flags messages with more links than MaxLinks, scoring each extra link
+type LinkCountCheck struct {
+	MaxLinks int
+}
+
+func (lc LinkCountCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	links := len(linkPattern.FindAllStringIndex(input.Request.Message, -1))
+	if links <= lc.MaxLinks {
+		return nil, nil
+	}
+	return &SpamSignal{Check: "links", Score: min(float64(links-lc.MaxLinks)*2, 6), Detail: fmt.Sprintf("%d links", links)}, nil
+}
+
+// BlocklistCheck flags messages containing blocked keywords or matching blocked patterns
+type BlocklistCheck struct {
+	Keywords []string
+	Patterns []*regexp.Regexp
+}
+
+func (bc BlocklistCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	text := strings.ToLower(input.Request.Name + "\n" + input.Request.Message)
+
+	var matches []string
+	for _, keyword := range bc.Keywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			matches = append(matches, keyword)
+		}
+	}
+	for _, pattern := range bc.Patterns {
+		if pattern.MatchString(text) {
+			matches = append(matches, pattern.String())
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	return &SpamSignal{Check: "blocklist", Score: 3 * float64(len(matches)), Detail: strings.Join(matches, ", ")}, nil
+}
+
+// DisposableEmailCheck flags senders using throwaway email providers
+type DisposableEmailCheck struct {
+	domains map[string]bool
+}
+
+func NewDisposableEmailCheck(domains []string) DisposableEmailCheck {
+	set := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		set[strings.ToLower(d)] = true
+	}
+	return DisposableEmailCheck{domains: set}
+}
+
+func (dc DisposableEmailCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	_, domain, ok := strings.Cut(strings.ToLower(input.Request.Email), "@")
+	if !ok {
+		return nil, nil
+	}
+	// match the domain and every parent domain
+	for d := domain; d != ""; {
+		if dc.domains[d] {
+			return &SpamSignal{Check: "disposable_email", Score: 5, Detail: domain}, nil
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+	return nil, nil
+}
+
+// CaptchaScoreCheck adds a signal for CAPTCHA scores that passed but are still low.
+// Providers without scores report 0 and are ignored.
+type CaptchaScoreCheck struct {
+	MinScore float64
+}
+
+func (cc CaptchaScoreCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	if input.CaptchaScore <= 0 || input.CaptchaScore >= cc.MinScore {
+		return nil, nil
+	}
+	return &SpamSignal{Check: "captcha_score", Score: 2, Detail: fmt.Sprintf("%.1f", input.CaptchaScore)}, nil
+}
+
+// DuplicateMessageCheck flags a message identical to one sent recently, counted with the
+// duplicate_message rate limit rule on a hash of the normalized message
+type DuplicateMessageCheck struct {
+	rateLimiter *RateLimitService
+}
+
+func (dc DuplicateMessageCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(input.Request.Message)), " ")
+	allowed, _, err := dc.rateLimiter.AllowKey(ctx, DuplicateMessageLimiter, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if allowed {
+		return nil, nil
+	}
+	return &SpamSignal{Check: "duplicate", Score: 4}, nil
+}
+
+// parseDomainList reads one domain per line, ignoring blank lines and # comments
+func parseDomainList(content string) []string {
+	var domains []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	return domains
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSpamCheck struct{}
+
+func (failingSpamCheck) Check(ctx context.Context, input *SpamInput) (*SpamSignal, error) {
+	return nil, errors.New("check unavailable")
+}
+
+func TestSpamChecks(t *testing.T) {
+	disposable := NewDisposableEmailCheck(parseDomainList(defaultDisposableDomains))
+	blocklist := BlocklistCheck{
+		Keywords: []string{"casino"},
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)seo\s+services`)},
+	}
+
+	tests := []struct {
+		name          string
+		check         SpamCheck
+		request       model.ContactRequest
+		captchaScore  float64
+		expectedCheck string
+		expectedScore float64
+	}{
+		{
+			name:          "honeypot filled",
+			check:         HoneypotCheck{},
+			request:       model.ContactRequest{Website: "http://spam.example.com"},
+			expectedCheck: "honeypot",
+			expectedScore: 10,
+		},
+		{
+			name:    "honeypot empty",
+			check:   HoneypotCheck{},
+			request: model.ContactRequest{},
+		},
+		{
+			name:          "too many links",
+			check:         LinkCountCheck{MaxLinks: 1},
+			request:       model.ContactRequest{Message: "see https://a.example and http://b.example and www.c.example"},
+			expectedCheck: "links",
+			expectedScore: 4,
+		},
+		{
+			name:    "links within limit",
+			check:   LinkCountCheck{MaxLinks: 1},
+			request: model.ContactRequest{Message: "my portfolio: https://a.example"},
+		},
+		{
+			name:          "blocked keyword and pattern",
+			check:         blocklist,
+			request:       model.ContactRequest{Message: "Best CASINO bonus and SEO  services!"},
+			expectedCheck: "blocklist",
+			expectedScore: 6,
+		},
+		{
+			name:          "disposable email subdomain",
+			check:         disposable,
+			request:       model.ContactRequest{Email: "bot@eu.mailinator.com"},
+			expectedCheck: "disposable_email",
+			expectedScore: 5,
+		},
+		{
+			name:    "regular email",
+			check:   disposable,
+			request: model.ContactRequest{Email: "jane@example.com"},
+		},
+		{
+			name:          "low captcha score",
+			check:         CaptchaScoreCheck{MinScore: 0.7},
+			captchaScore:  0.5,
+			expectedCheck: "captcha_score",
+			expectedScore: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal, err := tt.check.Check(context.Background(), &SpamInput{Request: &tt.request, CaptchaScore: tt.captchaScore})
+			assert.NoError(t, err)
+
+			if tt.expectedCheck == "" {
+				assert.Nil(t, signal)
+				return
+			}
+			assert.Equal(t, tt.expectedCheck, signal.Check)
+			assert.Equal(t, tt.expectedScore, signal.Score)
+		})
+	}
+}
+
+func TestFormTimingCheck(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	check := NewFormTimingCheck(config.StaticSecret("test-secret"), 3*time.Second, time.Hour)
+	check.now = func() time.Time { return now }
+	token := check.IssueToken(context.Background(), "test-session")
+	check.now = func() time.Time { return now.Add(time.Minute) }
+	futureToken := check.IssueToken(context.Background(), "test-session")
+
+	tests := []struct {
+		name          string
+		token         string
+		sessionID     string
+		elapsed       time.Duration
+		expectedScore float64
+	}{
+		{name: "human fill time", token: token, sessionID: "test-session", elapsed: 20 * time.Second},
+		{name: "filled too fast", token: token, sessionID: "test-session", elapsed: time.Second, expectedScore: 5},
+		{name: "missing token", token: "", sessionID: "test-session", expectedScore: 3},
+		{name: "forged token", token: "1735725600000.deadbeef", sessionID: "test-session", elapsed: time.Minute, expectedScore: 3},
+		{name: "token from another session", token: token, sessionID: "other-session", elapsed: 20 * time.Second, expectedScore: 3},
+		{name: "replayed without a session", token: token, elapsed: 20 * time.Second, expectedScore: 3},
+		{name: "replayed after the maximum age", token: token, sessionID: "test-session", elapsed: time.Hour + time.Second, expectedScore: 3},
+		{name: "dated in the future", token: futureToken, sessionID: "test-session", elapsed: 0, expectedScore: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check.now = func() time.Time { return now.Add(tt.elapsed) }
+			signal, err := check.Check(context.Background(), &SpamInput{Request: &model.ContactRequest{FormToken: tt.token}, SessionID: tt.sessionID})
+			assert.NoError(t, err)
+
+			if tt.expectedScore == 0 {
+				assert.Nil(t, signal)
+			} else {
+				assert.Equal(t, tt.expectedScore, signal.Score)
+			}
+		})
+	}
+}
+
+func TestSpamScorer_Score(t *testing.T) {
+	scorer := NewSpamScorer(5, HoneypotCheck{}, failingSpamCheck{}, LinkCountCheck{MaxLinks: 0})
+
+	verdict := scorer.Score(context.Background(), &SpamInput{Request: &model.ContactRequest{Message: "https://a.example"}})
+	assert.Equal(t, 2.0, verdict.Score)
+	assert.False(t, verdict.Quarantine)
+
+	verdict = scorer.Score(context.Background(), &SpamInput{Request: &model.ContactRequest{Website: "x", Message: "hi"}})
+	assert.Equal(t, 10.0, verdict.Score)
+	assert.True(t, verdict.Quarantine)
+	assert.Equal(t, "honeypot", verdict.Signals[0].Check)
+}
+
+func TestNewDefaultSpamScorer_FormTiming(t *testing.T) {
+	input := &SpamInput{Request: &model.ContactRequest{Message: "hi", Email: "jane@example.com"}, CaptchaScore: 0.9}
+	limiter := NewRateLimitService(nil, nil, nil)
+
+	scorer, err := NewDefaultSpamScorer(&config.Config{SpamThreshold: 5, SpamMaxLinks: 2}, limiter)
+	assert.NoError(t, err)
+	assert.Empty(t, scorer.IssueFormToken(context.Background(), "test-session"))
+	assert.Zero(t, scorer.Score(context.Background(), input).Score, "no form token is expected without a secret")
+
+	scorer, err = NewDefaultSpamScorer(&config.Config{SpamThreshold: 5, SpamMaxLinks: 2, FormTokenSecret: "secret"}, limiter)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, scorer.IssueFormToken(context.Background(), "test-session"))
+	assert.Equal(t, 3.0, scorer.Score(context.Background(), input).Score)
+}
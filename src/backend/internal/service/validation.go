@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"main/internal/model"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ValidateContactRequest normalizes the request fields in place and checks them
+// against their `validate` tags. It returns one error per invalid field.
+func ValidateContactRequest(contactReq *model.ContactRequest) []model.FieldError {
+	contactReq.Name = sanitizeText(contactReq.Name, false)
+	contactReq.Email = sanitizeText(contactReq.Email, false)
+	contactReq.Message = sanitizeText(contactReq.Message, true)
+	contactReq.Recaptcha = strings.TrimSpace(contactReq.Recaptcha)
+	contactReq.FormToken = strings.TrimSpace(contactReq.FormToken)
+
+	return validateStruct(contactReq)
+}
+
+// dangerousFormat are the invisible format characters stripped from text: bidi overrides,
+// embeddings, isolates and marks, which can disguise how text reads, and zero width
+// characters with no use in names or messages. Joiners are kept for emoji sequences and
+// scripts such as Persian or the Indic ones.
+var dangerousFormat = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x061c, Hi: 0x061c, Stride: 1}, // arabic letter mark
+		{Lo: 0x200b, Hi: 0x200b, Stride: 1}, // zero width space
+		{Lo: 0x200e, Hi: 0x200f, Stride: 1}, // left-to-right and right-to-left marks
+		{Lo: 0x202a, Hi: 0x202e, Stride: 1}, // bidi embeddings and overrides
+		{Lo: 0x2060, Hi: 0x2064, Stride: 1}, // word joiner and invisible operators
+		{Lo: 0x2066, Hi: 0x2069, Stride: 1}, // bidi isolates
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1}, // byte order mark
+		{Lo: 0xfff9, Hi: 0xfffb, Stride: 1}, // interlinear annotations
+	},
+}
+
+// sanitizeText applies Unicode NFC normalization, strips control characters and dangerous
+// format characters, and trims surrounding whitespace. Line breaks are normalized to \n and
+// tabs are kept when multiline is set, otherwise both become spaces.
+func sanitizeText(value string, multiline bool) string {
+	value = norm.NFC.String(strings.ToValidUTF8(value, ""))
+	value = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(value)
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			if multiline {
+				return r
+			}
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(dangerousFormat, r):
+			return -1
+		}
+		return r
+	}, value)
+	return strings.TrimSpace(value)
+}
+
+// validateStruct evaluates the required, email, min and max rules of the string fields' `validate` tags
+func validateStruct(v any) []model.FieldError {
+	var errs []model.FieldError
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		value := val.Field(i).String()
+
+		for rule := range strings.SplitSeq(tag, ",") {
+			if fieldErr := checkRule(name, value, rule); fieldErr != nil {
+				errs = append(errs, *fieldErr)
+				break // report the first failing rule per field
+			}
+		}
+	}
+	return errs
+}
+
+func checkRule(name, value, rule string) *model.FieldError {
+	ruleName, param, _ := strings.Cut(rule, "=")
+	switch ruleName {
+	case "required":
+		if value == "" {
+			return &model.FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s is required", name)}
+		}
+	case "email":
+		if value != "" && !isValidEmail(value) {
+			return &model.FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s must be a valid email address", name)}
+		}
+	case "min", "max":
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			return nil
+		}
+		length := utf8.RuneCountInString(value)
+		if ruleName == "min" && length < limit {
+			return &model.FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s must be at least %d characters", name, limit)}
+		}
+		if ruleName == "max" && length > limit {
+			return &model.FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s must be at most %d characters", name, limit)}
+		}
+	}
+	return nil
+}
+
+// isValidEmail checks the address format only, the domain is not looked up
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return false
+	}
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || len(local) == 0 || len(local) > 64 {
+		return false
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(email, "..") {
+		return false
+	}
+	return isValidHostname(domain)
+}
+
+// isValidHostname checks for an ASCII hostname with at least two labels
+func isValidHostname(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"main/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContactRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        model.ContactRequest
+		expectedFields []string
+		expectedRules  []string
+	}{
+		{
+			name:    "valid request",
+			request: model.ContactRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Message: "Hello!\nNice resume.", Recaptcha: "token"},
+		},
+		{
+			name:           "missing fields",
+			request:        model.ContactRequest{},
+			expectedFields: []string{"name", "email", "message", "recaptcha"},
+			expectedRules:  []string{"required", "required", "required", "required"},
+		},
+		{
+			name:           "invalid email",
+			request:        model.ContactRequest{Name: "Jane", Email: "Jane <jane@example.com>", Message: "Hi", Recaptcha: "token"},
+			expectedFields: []string{"email"},
+			expectedRules:  []string{"email"},
+		},
+		{
+			name:           "email without tld",
+			request:        model.ContactRequest{Name: "Jane", Email: "jane@localhost", Message: "Hi", Recaptcha: "token"},
+			expectedFields: []string{"email"},
+			expectedRules:  []string{"email"},
+		},
+		{
+			name:           "message too long",
+			request:        model.ContactRequest{Name: "Jane", Email: "jane@example.com", Message: strings.Repeat("a", 5001), Recaptcha: "token"},
+			expectedFields: []string{"message"},
+			expectedRules:  []string{"max"},
+		},
+		{
+			name:           "name of control characters only",
+			request:        model.ContactRequest{Name: "\x00\x07\u200b", Email: "jane@example.com", Message: "Hi", Recaptcha: "token"},
+			expectedFields: []string{"name"},
+			expectedRules:  []string{"required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateContactRequest(&tt.request)
+
+			var fields, rules []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+				rules = append(rules, e.Rule)
+			}
+			assert.Equal(t, tt.expectedFields, fields)
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	// "e" followed by a combining acute accent normalizes to a single "é"
+	assert.Equal(t, "Ren\u00e9", sanitizeText("  Rene\u0301\x1b ", false))
+	assert.Equal(t, "line one\nline two", sanitizeText("line one\r\nline two\x00", true))
+	assert.Equal(t, "Jane Doe", sanitizeText("Jane\nDoe", false))
+	assert.Equal(t, "Jane Doe", sanitizeText("Jane\r\nDoe", false))
+	assert.Equal(t, "Jane Doe", sanitizeText("Jane\tDoe", false))
+	assert.Equal(t, "line one\nline two", sanitizeText("line one\rline two", true))
+	assert.Equal(t, "a\tb", sanitizeText("a\tb", true))
+
+	// Bidi overrides and zero width spaces are stripped, joiners are kept
+	assert.Equal(t, "invoicefdp.exe", sanitizeText("invoice\u202efdp.exe", false))
+	assert.Equal(t, "ab", sanitizeText("a\u200bb\u2066", false))
+	assert.Equal(t, "\U0001F469\u200D\U0001F4BB", sanitizeText("\U0001F469\u200D\U0001F4BB", false))
+	assert.Equal(t, "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", sanitizeText("\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", true))
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"main/internal/metrics"
 	"main/internal/model"
 	"main/internal/storage"
+	"main/internal/tracing"
 )
 
 type VisitorService struct {
@@ -14,25 +16,51 @@ func NewVisitorService(storage storage.StorageInterface) *VisitorService {
 	return &VisitorService{storage: storage}
 }
 
-func (cs *VisitorService) GetVisitorCount(ctx context.Context) (int, error) {
+func (cs *VisitorService) GetVisitorCount(ctx context.Context) (count int, err error) {
+	ctx, span := tracing.Start(ctx, "VisitorService.GetVisitorCount")
+	defer func() { tracing.End(span, err) }()
+
 	return cs.storage.GetCount(ctx, "visitors")
 }
 
 // IncrementVisitorCount increments the visitor count if the user hasn't visited before
 // Returns the updated count and a status message indicating if the count was incremented
-func (cs *VisitorService) IncrementVisitorCount(ctx context.Context, session *model.UserSession) (int, string, error) {
+func (cs *VisitorService) IncrementVisitorCount(ctx context.Context, session *model.UserSession) (count int, action string, err error) {
+	ctx, span := tracing.Start(ctx, "VisitorService.IncrementVisitorCount")
+	defer func() { tracing.End(span, err) }()
+
 	// Check if user has already visited
 	if session.HasVisited {
 		// User has already visited, just return current count
-		count, err := cs.storage.GetCount(ctx, "visitors")
+		count, err = cs.storage.GetCount(ctx, "visitors")
 		return count, "already_visited", err
 	}
 
 	// User hasn't visited before, increment count
-	count, err := cs.storage.IncrementCount(ctx, "visitors")
+	count, err = cs.storage.IncrementCount(ctx, "visitors")
 	if err != nil {
 		return 0, "", err
 	}
+	metrics.Emit(nil, metrics.Count("Visits", 1))
 
 	return count, "incremented", nil
 }
+
+// RecordBotVisit counts a visit classified as bot traffic separately from real visitors
+// and returns the unchanged visitor count
+func (cs *VisitorService) RecordBotVisit(ctx context.Context) (count int, action string, err error) {
+	ctx, span := tracing.Start(ctx, "VisitorService.RecordBotVisit")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = cs.storage.IncrementCount(ctx, "bot_visits"); err != nil {
+		return 0, "", err
+	}
+	metrics.Emit(nil, metrics.Count("BotVisits", 1))
+
+	count, err = cs.storage.GetCount(ctx, "visitors")
+	if err != nil {
+		return 0, "", err
+	}
+
+	return count, "bot", nil
+}
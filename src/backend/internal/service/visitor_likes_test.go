@@ -192,6 +192,57 @@ func TestVisitorService_IncrementCount(t *testing.T) {
 	}
 }
 
+func TestVisitorService_RecordBotVisit(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockStorage)
+		expectedCount  int
+		expectedAction string
+		expectedError  bool
+		errorMessage   string
+	}{
+		{
+			name: "bot visit counted separately",
+			mockSetup: func(m *MockStorage) {
+				m.On("IncrementCount", mock.Anything, "bot_visits").Return(7, nil)
+				m.On("GetCount", mock.Anything, "visitors").Return(42, nil)
+			},
+			expectedCount:  42,
+			expectedAction: "bot",
+			expectedError:  false,
+		},
+		{
+			name: "storage error during bot increment",
+			mockSetup: func(m *MockStorage) {
+				m.On("IncrementCount", mock.Anything, "bot_visits").Return(0, errors.New("increment failed"))
+			},
+			expectedError: true,
+			errorMessage:  "increment failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockStorage{}
+			tt.mockSetup(mockStorage)
+
+			service := NewVisitorService(mockStorage)
+			count, action, err := service.RecordBotVisit(context.Background())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, count)
+				assert.Equal(t, tt.expectedAction, action)
+			}
+
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
 func TestLikeService_GetLikeCount(t *testing.T) {
 	tests := []struct {
 		name          string
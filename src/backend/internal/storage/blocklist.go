@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type BlocklistStorageInterface interface {
+	ListBlocklistEntries(ctx context.Context, entryType string) ([]model.BlocklistEntry, error)
+	PutBlocklistEntry(ctx context.Context, entry *model.BlocklistEntry) error
+	DeleteBlocklistEntry(ctx context.Context, entryType, value string) error
+}
+
+// BlocklistStorage keeps blocklist entries with Type as partition key and Value as sort key.
+// Expired entries are removed by the table's TTL on ExpiresAt.
+type BlocklistStorage struct {
+	client    DynamoDBAPI
+	tableName string
+}
+
+func NewBlocklistStorage(client DynamoDBAPI, tableName string) *BlocklistStorage {
+	return &BlocklistStorage{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// ListBlocklistEntries returns every entry of a type, following pagination
+func (s *BlocklistStorage) ListBlocklistEntries(ctx context.Context, entryType string) ([]model.BlocklistEntry, error) {
+	entries := []model.BlocklistEntry{}
+	var startKey map[string]types.AttributeValue
+	for {
+		response, err := s.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 &s.tableName,
+			KeyConditionExpression:    aws.String("#T = :type"),
+			ExpressionAttributeNames:  map[string]string{"#T": "Type"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":type": &types.AttributeValueMemberS{Value: entryType}},
+			ExclusiveStartKey:         startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var page []model.BlocklistEntry
+		if err := attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+
+		if len(response.LastEvaluatedKey) == 0 {
+			return entries, nil
+		}
+		startKey = response.LastEvaluatedKey
+	}
+}
+
+// PutBlocklistEntry adds an entry, replacing an existing one for the same value
+func (s *BlocklistStorage) PutBlocklistEntry(ctx context.Context, entry *model.BlocklistEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	})
+	return err
+}
+
+// DeleteBlocklistEntry removes an entry, ErrNotFound if there is none
+func (s *BlocklistStorage) DeleteBlocklistEntry(ctx context.Context, entryType, value string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"Type":  &types.AttributeValueMemberS{Value: entryType},
+			"Value": &types.AttributeValueMemberS{Value: value},
+		},
+		ConditionExpression: aws.String("attribute_exists(#T)"),
+		ExpressionAttributeNames: map[string]string{
+			"#T": "Type",
+		},
+	})
+	return notFoundOnConditionFailure(err)
+}
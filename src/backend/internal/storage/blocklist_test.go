@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlocklistStorage_ListBlocklistEntries(t *testing.T) {
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("Query", mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"Type":  &types.AttributeValueMemberS{Value: "email"},
+				"Value": &types.AttributeValueMemberS{Value: "spammer@example.com"},
+			},
+		},
+		LastEvaluatedKey: map[string]types.AttributeValue{
+			"Type":  &types.AttributeValueMemberS{Value: "email"},
+			"Value": &types.AttributeValueMemberS{Value: "spammer@example.com"},
+		},
+	}, nil).Once()
+	mockDB.On("Query", mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"Type":      &types.AttributeValueMemberS{Value: "email"},
+				"Value":     &types.AttributeValueMemberS{Value: "troll@example.com"},
+				"ExpiresAt": &types.AttributeValueMemberN{Value: "1735725600"},
+			},
+		},
+	}, nil).Once()
+
+	storage := NewBlocklistStorage(mockDB, "test-blocklist-table")
+	entries, err := storage.ListBlocklistEntries(context.Background(), model.BlockTypeEmail)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Nil(t, entries[0].ExpiresAt)
+	assert.Equal(t, time.Unix(1735725600, 0).UTC(), entries[1].ExpiresAt.UTC())
+	mockDB.AssertExpectations(t)
+}
+
+func TestBlocklistStorage_DeleteBlocklistEntry(t *testing.T) {
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("DeleteItem", mock.Anything, mock.Anything).Return(
+		&dynamodb.DeleteItemOutput{}, &types.ConditionalCheckFailedException{})
+
+	storage := NewBlocklistStorage(mockDB, "test-blocklist-table")
+	err := storage.DeleteBlocklistEntry(context.Background(), model.BlockTypeEmail, "nobody@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// digestStateID is the counters table item holding the digest state
+const digestStateID = "digest_state"
+
+type DigestStorageInterface interface {
+	GetDigestState(ctx context.Context) (*model.DigestState, error)
+	SaveDigestState(ctx context.Context, state *model.DigestState) error
+}
+
+// GetDigestState returns the state saved by the last digest, nil if no digest was sent yet
+func (s *Storage) GetDigestState(ctx context.Context) (*model.DigestState, error) {
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       counterKey(digestStateID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Item == nil {
+		return nil, nil
+	}
+
+	var state model.DigestState
+	err = attributevalue.UnmarshalMap(response.Item, &state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *Storage) SaveDigestState(ctx context.Context, state *model.DigestState) error {
+	state.ID = digestStateID
+	item, err := attributevalue.MarshalMap(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	})
+	return err
+}
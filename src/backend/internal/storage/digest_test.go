@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStorage_DigestState(t *testing.T) {
+	t.Run("no digest sent yet", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+		storage := New(mockDB, "test-table", "test-session-table")
+		state, err := storage.GetDigestState(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+	})
+
+	t.Run("existing state", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+			id, ok := input.Key["ID"].(*types.AttributeValueMemberS)
+			return ok && id.Value == digestStateID
+		})).Return(&dynamodb.GetItemOutput{
+			Item: map[string]types.AttributeValue{
+				"ID":       &types.AttributeValueMemberS{Value: digestStateID},
+				"Likes":    &types.AttributeValueMemberN{Value: "12"},
+				"Visitors": &types.AttributeValueMemberN{Value: "340"},
+				"SentAt":   &types.AttributeValueMemberN{Value: "1735725600"},
+			},
+		}, nil)
+
+		storage := New(mockDB, "test-table", "test-session-table")
+		state, err := storage.GetDigestState(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.DigestState{ID: digestStateID, Likes: 12, Visitors: 340, SentAt: time.Unix(1735725600, 0)}, state)
+	})
+
+	t.Run("save", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			id, ok := input.Item["ID"].(*types.AttributeValueMemberS)
+			return *input.TableName == "test-table" && ok && id.Value == digestStateID
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		storage := New(mockDB, "test-table", "test-session-table")
+		err := storage.SaveDigestState(context.Background(), &model.DigestState{Likes: 1, Visitors: 2, SentAt: time.Now()})
+
+		assert.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+}
@@ -2,8 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"main/internal/model"
 	"time"
 
@@ -18,8 +19,15 @@ type DynamoDBAPI interface {
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
 }
 
+// ErrNotFound is returned when an item to read or modify doesn't exist
+var ErrNotFound = errors.New("item not found")
+
 type StorageInterface interface {
 	GetCount(ctx context.Context, countName string) (int, error)
 	IncrementCount(ctx context.Context, countName string) (int, error)
@@ -55,44 +63,47 @@ func (s *Storage) GetSessionTable() string {
 	return s.sessionTable
 }
 
+// counterKey is the key of a counters table item; its ID is the counter's name or the name of a singleton item like the feature flags
+func counterKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"ID": &types.AttributeValueMemberS{Value: id}}
+}
+
 // retrieve count from DynamoDB and return json: {"count": ret} if successful
 func (s *Storage) GetCount(ctx context.Context, countName string) (int, error) {
-	// required argument for UpdateItemInput
-	key := map[string]types.AttributeValue{
-		"ID": &types.AttributeValueMemberS{Value: countName}, // Value is the name of the ID that we set for the counter in DynamoDB
-	}
-
-	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: &s.tableName, Key: key})
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: &s.tableName, Key: counterKey(countName)})
 	if err != nil {
 		return 0, err
 	}
 
 	// Check if item exists
 	if response.Item == nil {
-		return 0, fmt.Errorf("no item found with ID %s", countName)
+		return 0, fmt.Errorf("%w: no counter with ID %s", ErrNotFound, countName)
 	}
 
 	var vc model.Count
 	err = attributevalue.UnmarshalMap(response.Item, &vc)
 	if err != nil {
-		log.Printf("Couldn't unmarshal response. Here's why: %v\n", err)
+		slog.ErrorContext(ctx, "Couldn't unmarshal count", "count", countName, "error", err)
 		return 0, err
 	}
 
 	return vc.Count, nil
 }
 
-// use the DynamoDB client's UpdateItem() to increment the counter
+// use the DynamoDB client's UpdateItem() to increment the counter, creating it on first use
 // return the new incremented count as json: {"count": ret} if successful
 func (s *Storage) IncrementCount(ctx context.Context, countName string) (int, error) {
 
 	updateInput := dynamodb.UpdateItemInput{
-		TableName:                 &s.tableName,
-		Key:                       map[string]types.AttributeValue{"ID": &types.AttributeValueMemberS{Value: countName}}, // Value is the name of the ID that we set for the counter in DynamoDB
-		UpdateExpression:          aws.String("SET #C = #C + :val"),
-		ExpressionAttributeNames:  map[string]string{"#C": "Count"},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":val": &types.AttributeValueMemberN{Value: "1"}},
-		ReturnValues:              types.ReturnValueUpdatedNew, // Returns only the updated attributes, as they appear after theUpdateItem operation
+		TableName:                &s.tableName,
+		Key:                      counterKey(countName),
+		UpdateExpression:         aws.String("SET #C = if_not_exists(#C, :zero) + :val"),
+		ExpressionAttributeNames: map[string]string{"#C": "Count"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":val":  &types.AttributeValueMemberN{Value: "1"},
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew, // Returns only the updated attributes, as they appear after theUpdateItem operation
 	}
 	result, err := s.client.UpdateItem(ctx, &updateInput)
 	if err != nil {
@@ -110,7 +121,7 @@ func (s *Storage) IncrementCount(ctx context.Context, countName string) (int, er
 func (s *Storage) DecrementCount(ctx context.Context, countName string) (int, error) {
 	updateInput := dynamodb.UpdateItemInput{
 		TableName:                &s.tableName,
-		Key:                      map[string]types.AttributeValue{"ID": &types.AttributeValueMemberS{Value: countName}},
+		Key:                      counterKey(countName),
 		UpdateExpression:         aws.String("SET #C = if_not_exists(#C, :zero) - :val"),
 		ConditionExpression:      aws.String("#C > :zero"), // Prevent negative counts
 		ExpressionAttributeNames: map[string]string{"#C": "Count"},
@@ -169,11 +180,13 @@ func (s *Storage) GetUserSession(ctx context.Context, sessionID string) (*model.
 }
 
 func (s *Storage) CreateUserSession(ctx context.Context, sessionID string) error {
+	expiresAt := time.Now().Add(24 * time.Hour)
 	session := model.UserSession{
 		SessionID:  sessionID,
 		HasVisited: false,
 		HasLiked:   false,
-		ExpiresAt:  time.Now().Add(24 * time.Hour),
+		ExpiresAt:  expiresAt,
+		TTL:        expiresAt.Unix(),
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
@@ -210,3 +223,51 @@ func (s *Storage) UpdateUserSession(ctx context.Context, session *model.UserSess
 
 	return err
 }
+
+// DeleteExpiredSessions removes sessions created before sessions had a TTL attribute that
+// expired before now, up to limit per call. It returns how many sessions were deleted.
+// Other sessions are expired by the session table's TTL.
+func (s *Storage) DeleteExpiredSessions(ctx context.Context, now time.Time, limit int) (int, error) {
+	deleted := 0
+	var startKey map[string]types.AttributeValue
+	for {
+		response, err := s.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:                &s.sessionTable,
+			ProjectionExpression:     aws.String("SessionID, ExpiresAt"),
+			FilterExpression:         aws.String("attribute_not_exists(#ttl)"),
+			ExpressionAttributeNames: map[string]string{"#ttl": "TTL"},
+			ExclusiveStartKey:        startKey,
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		var sessions []model.UserSession
+		err = attributevalue.UnmarshalListOfMaps(response.Items, &sessions)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, session := range sessions {
+			if !session.ExpiresAt.Before(now) {
+				continue
+			}
+			_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: &s.sessionTable,
+				Key:       map[string]types.AttributeValue{"SessionID": &types.AttributeValueMemberS{Value: session.SessionID}},
+			})
+			if err != nil {
+				return deleted, err
+			}
+			deleted++
+			if deleted >= limit {
+				return deleted, nil
+			}
+		}
+
+		if len(response.LastEvaluatedKey) == 0 {
+			return deleted, nil
+		}
+		startKey = response.LastEvaluatedKey
+	}
+}
@@ -33,6 +33,26 @@ func (m *MockDynamoDBAPI) PutItem(ctx context.Context, params *dynamodb.PutItemI
 	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
 }
 
+func (m *MockDynamoDBAPI) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBAPI) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.QueryOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBAPI) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.ScanOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBAPI) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.DescribeTableOutput), args.Error(1)
+}
+
 func TestStorage_GetCount(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -233,7 +253,8 @@ func TestStorage_UserSession(t *testing.T) {
 		mockDB := new(MockDynamoDBAPI)
 
 		mockDB.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
-			return *input.TableName == "test-session-table"
+			ttl, ok := input.Item["TTL"].(*types.AttributeValueMemberN)
+			return *input.TableName == "test-session-table" && ok && ttl.Value != "0"
 		})).Return(&dynamodb.PutItemOutput{}, nil)
 
 		storage := New(mockDB, "test-table", "test-session-table")
@@ -259,3 +280,42 @@ func TestStorage_UserSession(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestStorage_DeleteExpiredSessions(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	sessionItem := func(id string, expiresAt time.Time) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"SessionID": &types.AttributeValueMemberS{Value: id},
+			"ExpiresAt": &types.AttributeValueMemberS{Value: expiresAt.Format(time.RFC3339Nano)},
+		}
+	}
+
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("Scan", mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		// Sessions with a TTL attribute are left to the table's TTL
+		return input.ExclusiveStartKey == nil && *input.FilterExpression == "attribute_not_exists(#ttl)"
+	})).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]types.AttributeValue{sessionItem("expired-1", now.Add(-time.Hour)), sessionItem("active", now.Add(time.Hour))},
+		LastEvaluatedKey: map[string]types.AttributeValue{"SessionID": &types.AttributeValueMemberS{Value: "active"}},
+	}, nil)
+	mockDB.On("Scan", mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{sessionItem("expired-2", now.Add(-24*time.Hour))},
+	}, nil)
+	mockDB.On("DeleteItem", mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	storage := New(mockDB, "test-table", "test-session-table")
+
+	t.Run("deletes expired sessions on every page", func(t *testing.T) {
+		deleted, err := storage.DeleteExpiredSessions(context.Background(), now, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		deleted, err := storage.DeleteExpiredSessions(context.Background(), now, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+	})
+}
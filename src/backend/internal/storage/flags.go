@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// featureFlagsID is the counters table item overriding the configured feature flags
+const featureFlagsID = "feature_flags"
+
+type FeatureFlagStorageInterface interface {
+	GetFeatureFlags(ctx context.Context) (map[string]string, error)
+}
+
+// GetFeatureFlags returns the flag overrides, nil if there are none
+func (s *Storage) GetFeatureFlags(ctx context.Context) (map[string]string, error) {
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       counterKey(featureFlagsID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Item == nil {
+		return nil, nil
+	}
+
+	var overrides model.FeatureFlagOverrides
+	err = attributevalue.UnmarshalMap(response.Item, &overrides)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.Flags, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStorage_GetFeatureFlags(t *testing.T) {
+	t.Run("no overrides", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+		storage := New(mockDB, "test-table", "test-session-table")
+		flags, err := storage.GetFeatureFlags(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, flags)
+	})
+
+	t.Run("existing overrides", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+			id, ok := input.Key["ID"].(*types.AttributeValueMemberS)
+			return *input.TableName == "test-table" && ok && id.Value == featureFlagsID
+		})).Return(&dynamodb.GetItemOutput{
+			Item: map[string]types.AttributeValue{
+				"ID": &types.AttributeValueMemberS{Value: featureFlagsID},
+				"Flags": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"likes":      &types.AttributeValueMemberS{Value: "off"},
+					"bot_visits": &types.AttributeValueMemberS{Value: "25%"},
+				}},
+			},
+		}, nil)
+
+		storage := New(mockDB, "test-table", "test-session-table")
+		flags, err := storage.GetFeatureFlags(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"likes": "off", "bot_visits": "25%"}, flags)
+	})
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type HealthStorageInterface interface {
+	CheckTable(ctx context.Context, tableName string) error
+}
+
+// HealthStorage checks that tables can be reached, without reading any item
+type HealthStorage struct {
+	client DynamoDBAPI
+}
+
+func NewHealthStorage(client DynamoDBAPI) *HealthStorage {
+	return &HealthStorage{client: client}
+}
+
+// CheckTable returns an error unless the table exists and is active
+func (s *HealthStorage) CheckTable(ctx context.Context, tableName string) error {
+	response, err := s.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &tableName})
+	if err != nil {
+		return fmt.Errorf("failed to describe table %s: %w", tableName, err)
+	}
+	if response.Table == nil || response.Table.TableStatus != types.TableStatusActive {
+		status := "unknown"
+		if response.Table != nil {
+			status = string(response.Table.TableStatus)
+		}
+		return fmt.Errorf("table %s is %s", tableName, status)
+	}
+	return nil
+}
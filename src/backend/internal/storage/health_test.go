@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthStorage_CheckTable(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        *dynamodb.DescribeTableOutput
+		err           error
+		expectedError string
+	}{
+		{
+			name:   "active",
+			output: &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: types.TableStatusActive}},
+		},
+		{
+			name:          "updating",
+			output:        &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: types.TableStatusUpdating}},
+			expectedError: "table test-table is UPDATING",
+		},
+		{
+			name:          "missing",
+			output:        &dynamodb.DescribeTableOutput{},
+			err:           &types.ResourceNotFoundException{},
+			expectedError: "failed to describe table test-table",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDynamoDBAPI)
+			mockDB.On("DescribeTable", mock.Anything, mock.MatchedBy(func(input *dynamodb.DescribeTableInput) bool {
+				return *input.TableName == "test-table"
+			})).Return(tt.output, tt.err)
+
+			err := NewHealthStorage(mockDB).CheckTable(context.Background(), "test-table")
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.err != nil {
+				assert.True(t, errors.As(err, new(*types.ResourceNotFoundException)))
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// inboxStatusIndex is the GSI with Status as partition key and CreatedAt as sort key
+const inboxStatusIndex = "StatusCreatedAtIndex"
+
+// ErrInvalidCursor is returned for pagination cursors that weren't issued by ListContactMessages
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type InboxStorageInterface interface {
+	CreateContactMessage(ctx context.Context, msg *model.ContactMessage) error
+	GetContactMessage(ctx context.Context, id string) (*model.ContactMessage, error)
+	ListContactMessages(ctx context.Context, query MessageQuery) ([]model.ContactMessage, string, error)
+	UpdateContactMessageStatus(ctx context.Context, id, status string) error
+	DeleteContactMessage(ctx context.Context, id string) error
+}
+
+// MessageQuery selects inbox messages with a status, created within [From, To], newest first.
+// Cursor is the opaque value returned by the previous page.
+type MessageQuery struct {
+	Status string
+	From   time.Time
+	To     time.Time
+	Limit  int32
+	Cursor string
+}
+
+// inboxCursor is the LastEvaluatedKey of a query on the status index
+type inboxCursor struct {
+	ID        string `dynamodbav:"ID" json:"id"`
+	Status    string `dynamodbav:"Status" json:"status"`
+	CreatedAt int64  `dynamodbav:"CreatedAt" json:"created_at"`
+}
+
+// InboxStorage keeps every contact form submission, keyed by message ID
+type InboxStorage struct {
+	client    DynamoDBAPI
+	tableName string
+}
+
+func NewInboxStorage(client DynamoDBAPI, tableName string) *InboxStorage {
+	return &InboxStorage{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (s *InboxStorage) CreateContactMessage(ctx context.Context, msg *model.ContactMessage) error {
+	item, err := attributevalue.MarshalMap(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &s.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ID)"), // Never overwrite a stored message
+	})
+	return err
+}
+
+// GetContactMessage returns the message with the given ID, nil if there is none
+func (s *InboxStorage) GetContactMessage(ctx context.Context, id string) (*model.ContactMessage, error) {
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       messageKey(id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Item == nil {
+		return nil, nil
+	}
+
+	var msg model.ContactMessage
+	err = attributevalue.UnmarshalMap(response.Item, &msg)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// ListContactMessages returns a page of messages matching the query and the cursor of the next page,
+// which is empty on the last page
+func (s *InboxStorage) ListContactMessages(ctx context.Context, query MessageQuery) ([]model.ContactMessage, string, error) {
+	keyCond := expression.Key("Status").Equal(expression.Value(query.Status)).
+		And(expression.Key("CreatedAt").Between(
+			expression.Value(query.From.Unix()),
+			expression.Value(query.To.Unix()),
+		))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build expression: %v", err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 &s.tableName,
+		IndexName:                 aws.String(inboxStatusIndex),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(false), // newest first
+		Limit:                     aws.Int32(query.Limit),
+	}
+	if query.Cursor != "" {
+		input.ExclusiveStartKey, err = decodeInboxCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	response, err := s.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages := []model.ContactMessage{}
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &messages)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next, err := encodeInboxCursor(response.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return messages, next, nil
+}
+
+// UpdateContactMessageStatus sets the status of an existing message, ErrNotFound if there is none
+func (s *InboxStorage) UpdateContactMessageStatus(ctx context.Context, id, status string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &s.tableName,
+		Key:                       messageKey(id),
+		UpdateExpression:          aws.String("SET #S = :status"),
+		ConditionExpression:       aws.String("attribute_exists(ID)"),
+		ExpressionAttributeNames:  map[string]string{"#S": "Status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":status": &types.AttributeValueMemberS{Value: status}},
+	})
+	return notFoundOnConditionFailure(err)
+}
+
+// DeleteContactMessage removes a message, ErrNotFound if there is none
+func (s *InboxStorage) DeleteContactMessage(ctx context.Context, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           &s.tableName,
+		Key:                 messageKey(id),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+	})
+	return notFoundOnConditionFailure(err)
+}
+
+func messageKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"ID": &types.AttributeValueMemberS{Value: id}}
+}
+
+func notFoundOnConditionFailure(err error) error {
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func encodeInboxCursor(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	var cursor inboxCursor
+	if err := attributevalue.UnmarshalMap(key, &cursor); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeInboxCursor(value string) (map[string]types.AttributeValue, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var cursor inboxCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return map[string]types.AttributeValue{
+		"ID":        &types.AttributeValueMemberS{Value: cursor.ID},
+		"Status":    &types.AttributeValueMemberS{Value: cursor.Status},
+		"CreatedAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(cursor.CreatedAt, 10)},
+	}, nil
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInboxStorage_CreateContactMessage(t *testing.T) {
+	msg := &model.ContactMessage{
+		ID:           "msg-1",
+		CreatedAt:    time.Unix(1735725600, 0),
+		Name:         "Jane",
+		Email:        "jane@example.com",
+		Message:      "Hello",
+		CaptchaScore: 0.9,
+		Status:       model.ContactStatusNew,
+	}
+
+	t.Run("successful create", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			createdAt, ok := input.Item["CreatedAt"].(*types.AttributeValueMemberN)
+			return *input.TableName == "test-inbox-table" && ok && createdAt.Value == "1735725600"
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		storage := NewInboxStorage(mockDB, "test-inbox-table")
+		err := storage.CreateContactMessage(context.Background(), msg)
+
+		assert.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("dynamodb error", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, errors.New("put failed"))
+
+		storage := NewInboxStorage(mockDB, "test-inbox-table")
+		err := storage.CreateContactMessage(context.Background(), msg)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInboxStorage_ListContactMessages(t *testing.T) {
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("Query", mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.TableName == "test-inbox-table" && *input.IndexName == inboxStatusIndex && !*input.ScanIndexForward
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"ID":        &types.AttributeValueMemberS{Value: "msg-1"},
+				"Status":    &types.AttributeValueMemberS{Value: "new"},
+				"CreatedAt": &types.AttributeValueMemberN{Value: "1735725600"},
+				"Email":     &types.AttributeValueMemberS{Value: "jane@example.com"},
+			},
+		},
+		LastEvaluatedKey: map[string]types.AttributeValue{
+			"ID":        &types.AttributeValueMemberS{Value: "msg-1"},
+			"Status":    &types.AttributeValueMemberS{Value: "new"},
+			"CreatedAt": &types.AttributeValueMemberN{Value: "1735725600"},
+		},
+	}, nil)
+
+	storage := NewInboxStorage(mockDB, "test-inbox-table")
+	messages, cursor, err := storage.ListContactMessages(context.Background(), MessageQuery{
+		Status: model.ContactStatusNew,
+		To:     time.Unix(1735800000, 0),
+		Limit:  10,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "jane@example.com", messages[0].Email)
+	assert.NotEmpty(t, cursor)
+
+	startKey, err := decodeInboxCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "msg-1"}, startKey["ID"])
+	mockDB.AssertExpectations(t)
+}
+
+func TestInboxStorage_UpdateContactMessageStatus(t *testing.T) {
+	t.Run("successful update", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		storage := NewInboxStorage(mockDB, "test-inbox-table")
+		err := storage.UpdateContactMessageStatus(context.Background(), "msg-1", model.ContactStatusRead)
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing message", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("UpdateItem", mock.Anything, mock.Anything).Return(
+			&dynamodb.UpdateItemOutput{}, &types.ConditionalCheckFailedException{})
+
+		storage := NewInboxStorage(mockDB, "test-inbox-table")
+		err := storage.UpdateContactMessageStatus(context.Background(), "msg-1", model.ContactStatusRead)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestInboxStorage_DeleteContactMessage(t *testing.T) {
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("DeleteItem", mock.Anything, mock.Anything).Return(
+		&dynamodb.DeleteItemOutput{}, &types.ConditionalCheckFailedException{})
+
+	storage := NewInboxStorage(mockDB, "test-inbox-table")
+	err := storage.DeleteContactMessage(context.Background(), "msg-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"main/internal/metrics"
+	"main/internal/tracing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// instrumentedClient traces every DynamoDB call and records its latency and errors
+type instrumentedClient struct {
+	client DynamoDBAPI
+}
+
+// Instrument wraps client so that every call gets a span and emits DynamoDBLatency and
+// DynamoDBErrors metrics, grouped by operation and table. Failed conditional writes are
+// expected and aren't errors.
+func Instrument(client DynamoDBAPI) DynamoDBAPI {
+	return &instrumentedClient{client: client}
+}
+
+// start starts the span of a call and returns the function that ends it
+func start(ctx context.Context, operation string, table *string) (context.Context, func(error)) {
+	ctx, span := tracing.Start(ctx, "DynamoDB."+operation,
+		attribute.String("db.system", "dynamodb"),
+		attribute.String("db.operation", operation),
+		attribute.String("aws.dynamodb.table_names", aws.ToString(table)),
+	)
+	begin := time.Now()
+	return ctx, func(err error) {
+		failed := 0
+		var conditionFailed *types.ConditionalCheckFailedException
+		if err != nil && !errors.As(err, &conditionFailed) {
+			failed = 1
+		} else {
+			err = nil
+		}
+		metrics.Emit(metrics.Dimensions{"Operation": operation, "Table": aws.ToString(table)},
+			metrics.Milliseconds("DynamoDBLatency", time.Since(begin)),
+			metrics.Count("DynamoDBErrors", failed),
+		)
+		tracing.End(span, err)
+	}
+}
+
+func (c *instrumentedClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	ctx, done := start(ctx, "GetItem", params.TableName)
+	out, err := c.client.GetItem(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	ctx, done := start(ctx, "UpdateItem", params.TableName)
+	out, err := c.client.UpdateItem(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	ctx, done := start(ctx, "PutItem", params.TableName)
+	out, err := c.client.PutItem(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	ctx, done := start(ctx, "DeleteItem", params.TableName)
+	out, err := c.client.DeleteItem(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	ctx, done := start(ctx, "Query", params.TableName)
+	out, err := c.client.Query(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	ctx, done := start(ctx, "Scan", params.TableName)
+	out, err := c.client.Scan(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
+
+func (c *instrumentedClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	ctx, done := start(ctx, "DescribeTable", params.TableName)
+	out, err := c.client.DescribeTable(ctx, params, optFns...)
+	done(err)
+	return out, err
+}
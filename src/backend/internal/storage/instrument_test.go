@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"main/internal/metrics"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInstrument(t *testing.T) {
+	var buf bytes.Buffer
+	metrics.SetDefault(metrics.New(&buf, "Test", "dev"))
+	defer metrics.SetDefault(nil)
+
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, errors.New("throttled")).Once()
+	mockDB.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, &types.ConditionalCheckFailedException{})
+
+	client := Instrument(mockDB)
+	client.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("counters")})
+	client.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("counters")})
+	client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("outbox")})
+
+	var records []map[string]any
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]any
+		assert.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, "GetItem", records[0]["Operation"])
+	assert.Equal(t, "counters", records[0]["Table"])
+	assert.Equal(t, float64(0), records[0]["DynamoDBErrors"])
+	assert.Contains(t, records[0], "DynamoDBLatency")
+	assert.Equal(t, float64(1), records[1]["DynamoDBErrors"])
+	assert.Equal(t, "PutItem", records[2]["Operation"])
+	assert.Equal(t, float64(0), records[2]["DynamoDBErrors"])
+	mockDB.AssertExpectations(t)
+}
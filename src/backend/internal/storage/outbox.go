@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// outboxStatusIndex is the GSI with Status as partition key and NextAttemptAt as sort key
+const outboxStatusIndex = "StatusNextAttemptAtIndex"
+
+// ErrConflict is returned when an outbox entry was changed by another worker
+var ErrConflict = errors.New("item was modified concurrently")
+
+type OutboxStorageInterface interface {
+	CreateOutboxEntry(ctx context.Context, entry *model.OutboxEntry) error
+	GetOutboxEntry(ctx context.Context, id string) (*model.OutboxEntry, error)
+	ListDueOutboxEntries(ctx context.Context, now time.Time, limit int32) ([]model.OutboxEntry, error)
+	UpdateOutboxEntry(ctx context.Context, entry *model.OutboxEntry, prevAttempts int) error
+}
+
+// OutboxStorage keeps notifications until they are delivered, keyed by entry ID
+type OutboxStorage struct {
+	client    DynamoDBAPI
+	tableName string
+}
+
+func NewOutboxStorage(client DynamoDBAPI, tableName string) *OutboxStorage {
+	return &OutboxStorage{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (s *OutboxStorage) CreateOutboxEntry(ctx context.Context, entry *model.OutboxEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &s.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ID)"),
+	})
+	return err
+}
+
+// GetOutboxEntry returns the entry with the given ID, nil if there is none
+func (s *OutboxStorage) GetOutboxEntry(ctx context.Context, id string) (*model.OutboxEntry, error) {
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.tableName,
+		Key:            messageKey(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Item == nil {
+		return nil, nil
+	}
+
+	var entry model.OutboxEntry
+	err = attributevalue.UnmarshalMap(response.Item, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListDueOutboxEntries returns up to limit pending entries whose next attempt is due, oldest first
+func (s *OutboxStorage) ListDueOutboxEntries(ctx context.Context, now time.Time, limit int32) ([]model.OutboxEntry, error) {
+	keyCond := expression.Key("Status").Equal(expression.Value(model.OutboxStatusPending)).
+		And(expression.Key("NextAttemptAt").LessThanEqual(expression.Value(now.Unix())))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %v", err)
+	}
+
+	response, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 &s.tableName,
+		IndexName:                 aws.String(outboxStatusIndex),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []model.OutboxEntry{}
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateOutboxEntry replaces an entry, provided its attempt count is still prevAttempts.
+// Workers use this as an optimistic lock so an entry is only attempted by one of them at a time.
+func (s *OutboxStorage) UpdateOutboxEntry(ctx context.Context, entry *model.OutboxEntry, prevAttempts int) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &s.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(ID) AND Attempts = :prev"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prev": &types.AttributeValueMemberN{Value: strconv.Itoa(prevAttempts)},
+		},
+	})
+
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxStorage_CreateOutboxEntry(t *testing.T) {
+	entry := &model.OutboxEntry{
+		ID:     "entry-1",
+		Status: model.OutboxStatusPending,
+		Payload: model.NotificationPayload{
+			Type: "contact",
+			Data: map[string]any{"name": "Jane"},
+		},
+		CreatedAt:     time.Unix(1735725600, 0),
+		NextAttemptAt: time.Unix(1735725600, 0),
+	}
+
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		next, ok := input.Item["NextAttemptAt"].(*types.AttributeValueMemberN)
+		_, hasPayload := input.Item["Payload"].(*types.AttributeValueMemberM)
+		return *input.TableName == "test-outbox-table" && ok && next.Value == "1735725600" && hasPayload &&
+			*input.ConditionExpression == "attribute_not_exists(ID)"
+	})).Return(&dynamodb.PutItemOutput{}, nil)
+
+	storage := NewOutboxStorage(mockDB, "test-outbox-table")
+	err := storage.CreateOutboxEntry(context.Background(), entry)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestOutboxStorage_ListDueOutboxEntries(t *testing.T) {
+	mockDB := new(MockDynamoDBAPI)
+	mockDB.On("Query", mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.IndexName == outboxStatusIndex && *input.Limit == 10
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"ID":            &types.AttributeValueMemberS{Value: "entry-1"},
+				"Status":        &types.AttributeValueMemberS{Value: model.OutboxStatusPending},
+				"Attempts":      &types.AttributeValueMemberN{Value: "2"},
+				"NextAttemptAt": &types.AttributeValueMemberN{Value: "1735725600"},
+				"Payload": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"Type": &types.AttributeValueMemberS{Value: "like"},
+				}},
+			},
+		},
+	}, nil)
+
+	storage := NewOutboxStorage(mockDB, "test-outbox-table")
+	entries, err := storage.ListDueOutboxEntries(context.Background(), time.Unix(1735725700, 0), 10)
+
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "entry-1", entries[0].ID)
+		assert.Equal(t, 2, entries[0].Attempts)
+		assert.Equal(t, "like", entries[0].Payload.Type)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestOutboxStorage_UpdateOutboxEntry(t *testing.T) {
+	entry := &model.OutboxEntry{ID: "entry-1", Status: model.OutboxStatusPending, Attempts: 3}
+
+	t.Run("successful update", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			prev, ok := input.ExpressionAttributeValues[":prev"].(*types.AttributeValueMemberN)
+			return ok && prev.Value == "2"
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		storage := NewOutboxStorage(mockDB, "test-outbox-table")
+		err := storage.UpdateOutboxEntry(context.Background(), entry, 2)
+		assert.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("PutItem", mock.Anything, mock.Anything).Return(
+			&dynamodb.PutItemOutput{}, &types.ConditionalCheckFailedException{})
+
+		storage := NewOutboxStorage(mockDB, "test-outbox-table")
+		err := storage.UpdateOutboxEntry(context.Background(), entry, 2)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+}
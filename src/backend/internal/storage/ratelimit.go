@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type RateLimitStorageInterface interface {
+	IncrementWindow(ctx context.Context, key string, expiresAt time.Time) (int, error)
+	GetWindow(ctx context.Context, key string) (int, error)
+}
+
+// RateLimitStorage keeps request counters per rate limit window so that limits
+// hold across Lambda containers. Items expire through the table's TTL on ExpiresAt.
+type RateLimitStorage struct {
+	client    DynamoDBAPI
+	tableName string
+}
+
+func NewRateLimitStorage(client DynamoDBAPI, tableName string) *RateLimitStorage {
+	return &RateLimitStorage{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// IncrementWindow atomically adds one hit to the window counter and returns the new count
+func (s *RateLimitStorage) IncrementWindow(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                &s.tableName,
+		Key:                      map[string]types.AttributeValue{"Key": &types.AttributeValueMemberS{Value: key}},
+		UpdateExpression:         aws.String("ADD #C :val SET #E = if_not_exists(#E, :exp)"),
+		ExpressionAttributeNames: map[string]string{"#C": "Count", "#E": "ExpiresAt"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":val": &types.AttributeValueMemberN{Value: "1"},
+			":exp": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment rate limit window: %v", err)
+	}
+
+	var count int
+	err = attributevalue.Unmarshal(result.Attributes["Count"], &count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetWindow returns the hit count of a window, 0 if the window has no hits
+func (s *RateLimitStorage) GetWindow(ctx context.Context, key string) (int, error) {
+	response, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       map[string]types.AttributeValue{"Key": &types.AttributeValueMemberS{Value: key}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if response.Item == nil {
+		return 0, nil
+	}
+
+	var count int
+	err = attributevalue.Unmarshal(response.Item["Count"], &count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimitStorage_IncrementWindow(t *testing.T) {
+	t.Run("successful increment", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("UpdateItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			key := input.Key["Key"].(*types.AttributeValueMemberS)
+			return *input.TableName == "test-ratelimit-table" && key.Value == "ip:abc#100"
+		})).Return(&dynamodb.UpdateItemOutput{
+			Attributes: map[string]types.AttributeValue{
+				"Count": &types.AttributeValueMemberN{Value: "3"},
+			},
+		}, nil)
+
+		storage := NewRateLimitStorage(mockDB, "test-ratelimit-table")
+		count, err := storage.IncrementWindow(context.Background(), "ip:abc#100", time.Now().Add(time.Hour))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("dynamodb error", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("UpdateItem", mock.Anything, mock.Anything).Return(
+			&dynamodb.UpdateItemOutput{}, errors.New("update failed"))
+
+		storage := NewRateLimitStorage(mockDB, "test-ratelimit-table")
+		_, err := storage.IncrementWindow(context.Background(), "ip:abc#100", time.Now().Add(time.Hour))
+
+		assert.Error(t, err)
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestRateLimitStorage_GetWindow(t *testing.T) {
+	t.Run("existing window", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: map[string]types.AttributeValue{
+				"Key":   &types.AttributeValueMemberS{Value: "ip:abc#100"},
+				"Count": &types.AttributeValueMemberN{Value: "7"},
+			},
+		}, nil)
+
+		storage := NewRateLimitStorage(mockDB, "test-ratelimit-table")
+		count, err := storage.GetWindow(context.Background(), "ip:abc#100")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7, count)
+	})
+
+	t.Run("missing window counts as zero", func(t *testing.T) {
+		mockDB := new(MockDynamoDBAPI)
+		mockDB.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+		storage := NewRateLimitStorage(mockDB, "test-ratelimit-table")
+		count, err := storage.GetWindow(context.Background(), "ip:abc#100")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
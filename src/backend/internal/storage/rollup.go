@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"main/internal/model"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+type RollupStorageInterface interface {
+	SaveCounterSnapshot(ctx context.Context, snapshot *model.CounterSnapshot) error
+}
+
+// SaveCounterSnapshot stores the day's snapshot in the counters table as "rollup#<date>",
+// replacing an earlier snapshot of the same day
+func (s *Storage) SaveCounterSnapshot(ctx context.Context, snapshot *model.CounterSnapshot) error {
+	snapshot.ID = "rollup#" + snapshot.Date
+	item, err := attributevalue.MarshalMap(snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	})
+	return err
+}
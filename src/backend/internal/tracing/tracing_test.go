@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	provider, err := Setup(context.Background(), Options{Exporter: ExporterStdout, Environment: "test", Writer: &buf})
+	assert.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+	assert.NoError(t, provider.Flush(context.Background()))
+
+	type span struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Parent      struct{ SpanID string }
+		Status      struct{ Code string }
+	}
+	var spans []span
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var s span
+		assert.NoError(t, decoder.Decode(&s))
+		spans = append(spans, s)
+	}
+
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "Error", spans[0].Status.Code)
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, spans[1].SpanContext.TraceID, spans[0].SpanContext.TraceID)
+}
+
+func TestSetup_None(t *testing.T) {
+	provider, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+	assert.NoError(t, err)
+	assert.NoError(t, provider.Flush(context.Background()))
+
+	_, err = Setup(context.Background(), Options{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestStartServer_ContinuesTrace(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Exporter: ExporterStdout, Writer: &bytes.Buffer{}})
+	assert.NoError(t, err)
+
+	headers := map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx, span := StartServer(context.Background(), "GET /api/session", headers)
+	defer span.End()
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(ctx))
+	assert.Equal(t, "", TraceID(context.Background()))
+}
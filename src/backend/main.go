@@ -2,44 +2,216 @@ package main
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	appConfig "main/internal/config"
 	"main/internal/handlers"
+	"main/internal/logging"
+	"main/internal/metrics"
 	"main/internal/service"
 	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"os"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	ses "github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
+	logging.Setup()
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+			fatal("Command failed", err, "command", os.Args[1])
+		}
+		return
+	}
+
+	ctx := context.Background()
+	awsCfg, appCfg, err := loadConfig(ctx, nil)
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+	tracer := setupTelemetry(ctx, appCfg)
+	// Services read secrets through appCfg.Secret, which picks up rotated secrets
+	router, _ := setup(awsCfg, appCfg)
+
+	lambda.Start(func(ctx context.Context, event json.RawMessage) (any, error) {
+		// Export this invocation's spans before Lambda freezes the process
+		defer tracer.Flush(ctx)
+		return router.HandleEvent(ctx, event)
+	})
+}
+
+// loadConfig reads the configuration, resolves its references to SSM parameters and Secrets
+// Manager secrets, or to the local secrets file when SECRETS_FILE is set, and validates it
+func loadConfig(ctx context.Context, args []string) (aws.Config, *appConfig.Config, error) {
+	appCfg, err := appConfig.Parse(args)
 	if err != nil {
-		log.Fatalf("Couldn't load AWS config: %s", err)
+		return aws.Config{}, nil, err
 	}
 
-	appCfg := appConfig.Load()
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return aws.Config{}, nil, fmt.Errorf("couldn't load AWS config: %w", err)
+	}
+
+	providers := map[string]appConfig.SecretProvider{
+		appConfig.SecretSchemeSSM:            appConfig.NewSSMProvider(ssm.NewFromConfig(traced(awsCfg))),
+		appConfig.SecretSchemeSecretsManager: appConfig.NewSecretsManagerProvider(secretsmanager.NewFromConfig(traced(awsCfg))),
+	}
+	if appCfg.SecretsFile != "" {
+		if providers, err = appConfig.LoadFileSecrets(appCfg.SecretsFile); err != nil {
+			return aws.Config{}, nil, fmt.Errorf("SECRETS_FILE: %w", err)
+		}
+	}
+
+	secrets := appConfig.NewSecretResolver(appCfg.SecretsRefreshInterval, providers)
+	if err := appCfg.ResolveSecrets(ctx, secrets); err != nil {
+		return aws.Config{}, nil, err
+	}
+	return awsCfg, appCfg, appCfg.Validate()
+}
+
+// traced returns a copy of cfg whose clients trace every call with the SDK's OpenTelemetry
+// middleware. DynamoDB is traced by storage.Instrument instead, which also records metrics.
+func traced(cfg aws.Config) aws.Config {
+	cfg = cfg.Copy()
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
+	return cfg
+}
+
+// setupTelemetry applies the log level and sets up metrics and tracing
+func setupTelemetry(ctx context.Context, appCfg *appConfig.Config) *tracing.Provider {
+	logging.SetLevel(logging.ParseLevel(appCfg.LogLevel, appCfg.Environment))
+	metrics.SetDefault(metrics.New(os.Stdout, metrics.Namespace, appCfg.Environment))
+	tracer, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    appCfg.TracingExporter,
+		Endpoint:    appCfg.TracingEndpoint,
+		Environment: appCfg.Environment,
+	})
+	if err != nil {
+		fatal("Couldn't set up tracing", err)
+	}
+	return tracer
+}
 
+// setup creates the AWS clients, storage, services and handlers, and returns the Lambda
+// entry point and the background jobs
+func setup(cfg aws.Config, appCfg *appConfig.Config) (*handlers.EventRouter, *service.JobRegistry) {
 	// Initialize AWS clients
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	sesClient := ses.NewFromConfig(cfg)
-	snsClient := sns.NewFromConfig(cfg)
+	dynamoClient := storage.Instrument(dynamodb.NewFromConfig(cfg))
+	sesClient := ses.NewFromConfig(traced(cfg))
+	snsClient := sns.NewFromConfig(traced(cfg))
 
 	// Initialize storage
 	store := storage.New(dynamoClient, appCfg.DynamoDBTable, appCfg.SessionTable)
+	rateLimitStore := storage.NewRateLimitStorage(dynamoClient, appCfg.RateLimitTable)
+	inboxStore := storage.NewInboxStorage(dynamoClient, appCfg.InboxTable)
+	var outboxStore storage.OutboxStorageInterface
+	if appCfg.OutboxTable != "" {
+		outboxStore = storage.NewOutboxStorage(dynamoClient, appCfg.OutboxTable)
+	}
+	var blocklistStore storage.BlocklistStorageInterface
+	if appCfg.BlocklistTable != "" {
+		blocklistStore = storage.NewBlocklistStorage(dynamoClient, appCfg.BlocklistTable)
+	}
 
 	// Initialize services
 	sessionService := service.NewSessionService(store)
 	visitorService := service.NewVisitorService(store)
 	likesService := service.NewLikeService(store)
-	contactService := service.NewContactService(appCfg)
-	notificationService := service.NewNotificationService(sesClient, snsClient, appCfg)
+	csrfService := service.NewCSRFService(appCfg.Secret("CSRF_SECRET"))
+	blocklistService := service.NewBlocklistService(blocklistStore, appCfg.BlocklistCacheTTL)
+
+	rateLimits := appCfg.RateLimits
+	if appCfg.RateLimitTable == "" {
+		slog.Warn("RATE_LIMIT_TABLE not set, rate limiting disabled")
+		rateLimits = nil
+	}
+	rateLimitService := service.NewRateLimitService(rateLimitStore, rateLimits, blocklistService)
+	var featureFlagStore storage.FeatureFlagStorageInterface
+	if appCfg.FeatureFlagsDynamoDB {
+		featureFlagStore = store
+	}
+	featureFlags := service.NewFeatureFlagService(appCfg.FeatureFlags, featureFlagStore, appCfg.FeatureFlagsCacheTTL)
+	notificationService := service.NewNotificationService(sesClient, snsClient, rateLimitService, featureFlags, appCfg)
+
+	spamScorer, err := service.NewDefaultSpamScorer(appCfg, rateLimitService)
+	if err != nil {
+		fatal("Couldn't create spam scorer", err)
+	}
+	httpClient := &http.Client{Timeout: appCfg.CaptchaTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	contactService, err := service.NewContactService(appCfg, inboxStore, spamScorer, blocklistService, httpClient, appCfg.CaptchaVerifyURL)
+	if err != nil {
+		fatal("Couldn't create contact service", err)
+	}
+
+	crawlerRanges, err := service.LoadCrawlerRanges(appCfg.BotIPRangesFile)
+	if err != nil {
+		fatal("Couldn't load crawler IP ranges", err)
+	}
+	botDetector := service.NewBotDetector(crawlerRanges, appCfg.BotMinSessionAge)
+	notifyClient := &http.Client{Timeout: appCfg.NotificationTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	notificationDispatcher := service.NewDefaultNotificationDispatcher(appCfg, notificationService, notifyClient)
+	outboxService := service.NewOutboxService(outboxStore, notificationDispatcher, notificationService, appCfg)
+	digestService := service.NewDigestService(store, store, outboxService, featureFlags, appCfg)
+	maintenanceService := service.NewMaintenanceService(store, store, store, appCfg)
+	inboxService := service.NewInboxService(inboxStore)
+	adminAuthService := service.NewAdminAuthService(appCfg.Secret("ADMIN_API_TOKEN"))
+	healthService := service.NewHealthService(appCfg, storage.NewHealthStorage(dynamoClient), sesClient)
 
 	// Initialize handler
-	apiHandler := handlers.NewAPIHandler(sessionService, visitorService, likesService, contactService, notificationService)
+	apiHandler := handlers.NewAPIHandler(
+		sessionService,
+		visitorService,
+		likesService,
+		contactService,
+		notificationService,
+		csrfService,
+		rateLimitService,
+		botDetector,
+		inboxService,
+		adminAuthService,
+		blocklistService,
+		outboxService,
+		healthService,
+		featureFlags,
+		appCfg.SiteURL,
+	)
+
+	// Register background jobs
+	jobs := service.NewJobRegistry(appCfg.Jobs)
+	jobs.Register(service.JobDigest, func(ctx context.Context, _ appConfig.JobConfig) error {
+		return digestService.SendDigest(ctx)
+	})
+	jobs.Register(service.JobCounterRollup, func(ctx context.Context, _ appConfig.JobConfig) error {
+		return maintenanceService.RollupCounters(ctx)
+	})
+	jobs.Register(service.JobSessionCleanup, func(ctx context.Context, job appConfig.JobConfig) error {
+		return maintenanceService.CleanupSessions(ctx, job.BatchSize)
+	})
+	jobs.Register(service.JobOutboxRetry, func(ctx context.Context, job appConfig.JobConfig) error {
+		attempted, err := outboxService.ProcessDue(ctx, int32(job.BatchSize))
+		slog.InfoContext(ctx, "Outbox worker finished", "attempted", attempted)
+		return err
+	})
+
+	return handlers.NewEventRouter(apiHandler, outboxService, jobs), jobs
+}
 
-	lambda.Start(apiHandler.HandleRequest)
+// fatal logs err and exits
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append(args, "error", err)...)
+	os.Exit(1)
 }
@@ -1,5 +1,5 @@
 #!/bin/bash
-GOOS=linux GOARCH=amd64 go build -tags lambda.norpc -o bootstrap main.go
+GOOS=linux GOARCH=amd64 go build -tags lambda.norpc -o bootstrap .
 zip myFunction.zip bootstrap
 aws lambda update-function-code \
     --function-name Cloud-Resume-API-Handler-Lambda \
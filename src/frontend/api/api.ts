@@ -4,8 +4,22 @@ const visitorCountElement = document.getElementById('visitor-count') as HTMLElem
 export interface SessionStatus {
     has_visited: boolean;
     has_liked: boolean;
+    csrf_token: string;
+    form_token: string;
+    features?: Features;
 }
 
+// Features enabled for this session; a missing entry counts as on
+export interface Features {
+    likes?: boolean;
+    contact?: boolean;
+}
+
+// CSRF token issued by /session, required by every state-changing request
+let csrfToken = '';
+// Token timing how long the contact form takes to fill in
+let formToken = '';
+
 export const api = {
     async getSession(): Promise<SessionStatus> {
         const res = await fetch(`${baseURL}/session`, {
@@ -18,6 +32,8 @@ export const api = {
             console.log("Error fetching session: ", error);
             throw error;
         });
+        csrfToken = res.csrf_token;
+        formToken = res.form_token;
         return res;
     },
 
@@ -39,6 +55,9 @@ export const api = {
             method: 'POST',
             mode: 'cors',
             credentials: 'include',
+            headers: {
+                'X-CSRF-Token': csrfToken,
+            },
         })
         .then(response => response.json())
         .catch(error => {
@@ -66,6 +85,9 @@ export const api = {
             method: 'POST',
             mode: 'cors',
             credentials: 'include',
+            headers: {
+                'X-CSRF-Token': csrfToken,
+            },
         })
         .then(response => response.json())
         .catch(error => {
@@ -75,15 +97,16 @@ export const api = {
         return res.count.toString();
     },
 
-    async sendContact(form: { name: string; email: string; message: string; recaptcha: string }) : Promise<Response> {
+    async sendContact(form: { name: string; email: string; message: string; recaptcha: string; website: string }) : Promise<Response> {
         return fetch(`${baseURL}/contact`, {
             method: 'POST',
             mode: 'cors',
             credentials: 'include',
             headers: {
                 'Content-Type': 'application/json',
+                'X-CSRF-Token': csrfToken,
             },
-            body: JSON.stringify(form)
+            body: JSON.stringify({ ...form, form_token: formToken })
         });
     }
 }
\ No newline at end of file
@@ -3,7 +3,7 @@ import { getRecaptchaToken } from '../utils/recaptcha';
 
 const RECAPTCHA_SITE_KEY = '6LeKHXorAAAAAKEb3mnixv6VTJDHjMgNoFnz1ksy';
 
-export function setupContactForm(container: HTMLElement) {
+export function setupContactForm(container: HTMLElement): HTMLElement {
     const contactDiv = document.createElement('div');
     contactDiv.id = 'contact-form-board';
     contactDiv.innerHTML = `
@@ -23,6 +23,7 @@ export function setupContactForm(container: HTMLElement) {
                 <input name="name" placeholder="Your Name" required>
                 <input name="email" type="email" placeholder="Your Email" required>
                 <textarea name="message" placeholder="Your Message" required rows="3"></textarea>
+                <input name="website" tabindex="-1" autocomplete="off" aria-hidden="true" style="position: absolute; left: -9999px;">
                 <div id="contact-form-actions">
                     <button type="submit">Send Message</button>
                     <span id="contact-status"></span>
@@ -66,6 +67,7 @@ export function setupContactForm(container: HTMLElement) {
             email: formData.get('email') as string,
             message: formData.get('message') as string,
             recaptcha: await getRecaptchaToken(RECAPTCHA_SITE_KEY),
+            website: formData.get('website') as string,
         };
         const res = await api.sendContact(data);
         if (res.ok) {
@@ -78,4 +80,6 @@ export function setupContactForm(container: HTMLElement) {
             status.textContent = 'Error sending message.';
         }
     };
+
+    return contactDiv;
 }
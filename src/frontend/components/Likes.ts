@@ -70,6 +70,11 @@ class LikeCounter {
         }
     }
 
+    // hide the like button when the likes feature is turned off
+    hide() {
+        this.likeDiv.style.display = 'none';
+    }
+
     // only called once after the page has loaded
     updateLikeSessionStatus(has_liked: boolean) {
         // If the user has liked, update the visual state
@@ -127,17 +127,26 @@ document.addEventListener('DOMContentLoaded', async () => {
     app.appendChild(skillsSection);
 
 
+    const contactForm = setupContactForm(app);
+
     // Session handling
     // Check if this user has visited or liked before
     api.getSession().then(sessionStatus => {
         // Update visitor and like counters based on this user's session
         visitorCounter.updateVisitorSessionStatus(sessionStatus.has_visited);
         likeCounter.updateLikeSessionStatus(sessionStatus.has_liked);
+
+        // Hide the features the backend has turned off
+        if (sessionStatus.features?.likes === false) {
+            likeCounter.hide();
+        }
+        if (sessionStatus.features?.contact === false) {
+            contactForm.style.display = 'none';
+        }
     }).catch(error => {
         console.error('Failed to fetch session status:', error);
         // Fallback: assume first-time visitor and not liked
         visitorCounter.updateVisitorSessionStatus(false);
         likeCounter.updateLikeSessionStatus(false);
     });
-    setupContactForm(app);
 });